@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/batch"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func runBatch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	output := flags.String("out", "", "output directory (default: no outputs)")
+	formats := flags.String("formats", "json,srt,vtt,txt", "comma-separated output formats")
+	results := flags.String("results", "", "results manifest, CSV or JSON (default: <manifest>.results.csv)")
+	concurrency := flags.Int("concurrency", 4, "rows transcribed at once")
+	cancelRemote := flags.Bool("cancel-remote", false, "delete unfinished transcriptions when interrupted")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gladia batch [flags] <manifest.csv|manifest.json>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	manifest, err := batch.ReadManifest(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	if *results == "" {
+		path := flags.Arg(0)
+		*results = strings.TrimSuffix(path, filepath.Ext(path)) + ".results.csv"
+	}
+
+	var waitOptions []gladia.WaitOption
+	if *cancelRemote {
+		waitOptions = append(waitOptions, gladia.WithCancelRemoteOnContextDone())
+	}
+
+	outcomes := batch.Run(ctx, client, manifest, batch.Options{
+		Concurrency: *concurrency,
+		OutputDir:   *output,
+		Formats:     strings.Split(*formats, ","),
+		WaitOptions: waitOptions,
+		OnResult: func(r batch.Result) {
+			if r.Error != "" {
+				fmt.Fprintf(os.Stderr, "row %d %s: %s\n", r.Row, r.Source, r.Error)
+				return
+			}
+			fmt.Printf("row %d %s: %s %s\n", r.Row, r.Source, r.Status, r.TranscriptionID)
+		},
+	})
+	if err := batch.WriteResults(*results, outcomes); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range outcomes {
+		if r.Status != gladia.StatusDone {
+			failed++
+		}
+	}
+	fmt.Printf("%d rows, %d failed, results in %s\n", len(outcomes), failed, *results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", failed, len(outcomes))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Command gladia transcribes audio with the Gladia API.
+//
+// Usage:
+//
+//	gladia <command> [flags]
+//
+// The API key is read from the GLADIA_API_KEY environment variable, or from the file
+// named by GLADIA_API_KEY_FILE.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"batch", "transcribe the recordings listed in a CSV or JSON manifest", runBatch},
+	{"purge", "delete Gladia-side transcriptions matching a retention policy", runPurge},
+	{"relay", "receive callbacks and relay them to development clients", runRelay},
+	{"watch", "transcribe audio files dropped into a directory", runWatch},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+		if err := cmd.run(ctx, os.Args[2:]); err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Fprintf(os.Stderr, "gladia %s: %v\n", cmd.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gladia <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// newClient creates a client from the environment. A key file is re-read when it
+// changes, so long-running commands pick up rotated keys.
+func newClient() (*gladia.Client, error) {
+	var credentials gladia.CredentialProvider
+	switch {
+	case os.Getenv("GLADIA_API_KEY_FILE") != "":
+		credentials = gladia.NewFileKey(os.Getenv("GLADIA_API_KEY_FILE"))
+	case os.Getenv("GLADIA_API_KEY") != "":
+		credentials = gladia.EnvKey("GLADIA_API_KEY")
+	default:
+		return nil, errors.New("GLADIA_API_KEY or GLADIA_API_KEY_FILE must be set")
+	}
+
+	opts := []gladia.ClientOption{gladia.WithCredentialProvider(credentials)}
+	if baseURL := os.Getenv("GLADIA_BASE_URL"); baseURL != "" {
+		opts = append(opts, gladia.WithBaseURL(baseURL))
+	}
+	return gladia.NewClient("", opts...), nil
+}
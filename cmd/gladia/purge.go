@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/retention"
+)
+
+func runPurge(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	olderThan := flags.Duration("older-than", 0, "delete transcriptions created longer ago than this, e.g. 720h")
+	status := flags.String("status", "", "comma-separated statuses to delete (default: any)")
+	metadata := flags.String("metadata", "", `custom metadata the transcriptions must carry, as JSON, e.g. {"team":"sales"}`)
+	dryRun := flags.Bool("dry-run", false, "report what would be deleted without deleting")
+	concurrency := flags.Int("concurrency", 4, "deletions in flight")
+	rate := flags.Float64("rate", 5, "deletions per second, 0 for no limit")
+	report := flags.String("report", "", "write the JSON report to this file (default: stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gladia purge [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	opts := retention.Options{
+		Policy:      retention.Policy{OlderThan: *olderThan},
+		DryRun:      *dryRun,
+		Concurrency: *concurrency,
+		Rate:        *rate,
+	}
+	if *status != "" {
+		opts.Status = strings.Split(*status, ",")
+	}
+	if *metadata != "" {
+		if err := json.Unmarshal([]byte(*metadata), &opts.CustomMetadata); err != nil {
+			return fmt.Errorf("invalid metadata filter: %w", err)
+		}
+	}
+	if opts.OlderThan == 0 && len(opts.Status) == 0 && len(opts.CustomMetadata) == 0 {
+		return errors.New("refusing to delete every transcription, set -older-than, -status or -metadata")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	result, runErr := retention.New(client, opts).Run(ctx)
+	out := os.Stdout
+	if *report != "" {
+		file, err := os.Create(*report)
+		if err != nil {
+			return fmt.Errorf("failed to create report: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := result.WriteJSON(out); err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(os.Stderr, "scanned %d, would delete %d\n", result.Scanned, len(result.Selected))
+	} else {
+		fmt.Fprintf(os.Stderr, "scanned %d, deleted %d, failed %d\n", result.Scanned, result.Deleted, len(result.Failed))
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d deletions failed", len(result.Failed))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/relay"
+	"github.com/fulviodenza/go-gladia-client/pkg/webhook"
+)
+
+func runRelay(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("relay", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	logFile := flags.String("log", "", "JSON lines file persisting received callbacks")
+	maxRecords := flags.Int("max-records", 1000, "number of recent callbacks kept for clients")
+	token := flags.String("token", "", "require this token in the callback URL query and from clients; required unless -addr is loopback-only")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gladia relay [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	opts := []relay.Option{relay.WithMaxRecords(*maxRecords)}
+	if *logFile != "" {
+		opts = append(opts, relay.WithLogFile(*logFile))
+	}
+	switch {
+	case *token != "":
+		opts = append(opts, relay.WithClientToken(*token), relay.WithWebhookOptions(webhook.WithToken(*token)))
+	case isLoopback(*addr):
+		opts = append(opts, relay.WithInsecure())
+	default:
+		return fmt.Errorf("relay on %s is reachable from other hosts; set -token or listen on 127.0.0.1", *addr)
+	}
+	server, err := relay.NewServer(opts...)
+	if err != nil {
+		return err
+	}
+	defer server.Close()
+
+	httpServer := &http.Server{Addr: *addr, Handler: server}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	fmt.Printf("relaying callbacks posted to %s%s\n", *addr, relay.CallbackPath)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopback reports whether addr only accepts connections from this machine
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
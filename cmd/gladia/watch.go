@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/watch"
+)
+
+func runWatch(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	profile := flags.String("profile", "", "default options profile (JSON) for folders without "+watch.ProfileFile)
+	formats := flags.String("formats", strings.Join(watch.DefaultFormats, ","), "comma-separated output formats")
+	output := flags.String("out", "", "output directory (default: next to the processed file)")
+	done := flags.String("done", "done", "folder receiving transcribed files")
+	failed := flags.String("failed", "failed", "folder receiving files that failed")
+	interval := flags.Duration("interval", 2*time.Second, "how often the directory is scanned")
+	stable := flags.Duration("stable", 5*time.Second, "how long a file size must stay unchanged")
+	concurrency := flags.Int("concurrency", 2, "files transcribed at once")
+	recursive := flags.Bool("recursive", false, "also watch subfolders")
+	cancelRemote := flags.Bool("cancel-remote", false, "delete unfinished transcriptions when interrupted")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gladia watch [flags] <dir>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	opts := watch.Options{
+		OutputDir:    *output,
+		DoneDir:      *done,
+		FailedDir:    *failed,
+		PollInterval: *interval,
+		StableFor:    *stable,
+		Concurrency:  *concurrency,
+		Recursive:    *recursive,
+		OnEvent:      printEvent,
+	}
+	if *cancelRemote {
+		opts.WaitOptions = append(opts.WaitOptions, gladia.WithCancelRemoteOnContextDone())
+	}
+	if *profile != "" {
+		p, err := watch.LoadProfile(*profile)
+		if err != nil {
+			return err
+		}
+		opts.Profile = *p
+	}
+	if len(opts.Profile.Formats) == 0 {
+		opts.Profile.Formats = strings.Split(*formats, ",")
+	}
+
+	return watch.New(client, flags.Arg(0), opts).Run(ctx)
+}
+
+func printEvent(event watch.Event) {
+	switch event.Kind {
+	case watch.EventStarted:
+		fmt.Printf("transcribing %s\n", event.Path)
+	case watch.EventDone:
+		fmt.Printf("done %s -> %s\n", event.Path, strings.Join(event.Outputs, ", "))
+	case watch.EventFailed:
+		fmt.Fprintf(os.Stderr, "failed %s: %v\n", event.Path, event.Err)
+	}
+}
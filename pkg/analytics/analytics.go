@@ -0,0 +1,237 @@
+package analytics
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Options configures how conversations are analyzed
+type Options struct {
+	// Speakers merges and names diarized speakers before analysis
+	Speakers *gladia.SpeakerMap
+	// MinSilence is the shortest gap, in seconds, reported as silence. Defaults to 2 seconds.
+	MinSilence float64
+	// MinInterruption is the shortest overlap, in seconds, counted as an interruption
+	// rather than backchannel. Defaults to 0.5 seconds.
+	MinInterruption float64
+}
+
+// SpeakerStats holds the statistics of a single speaker. Durations are in seconds.
+type SpeakerStats struct {
+	Speaker           int     `json:"speaker"`
+	Name              string  `json:"name"`
+	TalkTime          float64 `json:"talk_time"`
+	TalkRatio         float64 `json:"talk_ratio"`
+	TalkListenRatio   float64 `json:"talk_listen_ratio"`
+	Turns             int     `json:"turns"`
+	Words             int     `json:"words"`
+	WordsPerMinute    float64 `json:"words_per_minute"`
+	AverageConfidence float64 `json:"average_confidence"`
+	LongestMonologue  float64 `json:"longest_monologue"`
+	Interruptions     int     `json:"interruptions"`
+	Interrupted       int     `json:"interrupted"`
+}
+
+// Gap is a silence between two utterances
+type Gap struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Duration float64 `json:"duration"`
+}
+
+// Overlap is a span where two speakers talk at once
+type Overlap struct {
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Speakers [2]int  `json:"speakers"`
+}
+
+// Monologue is an uninterrupted run of turns by the same speaker
+type Monologue struct {
+	Speaker  int     `json:"speaker"`
+	Start    float64 `json:"start"`
+	End      float64 `json:"end"`
+	Duration float64 `json:"duration"`
+}
+
+// Report holds conversation statistics computed from utterances
+type Report struct {
+	Duration         float64        `json:"duration"`
+	TalkTime         float64        `json:"talk_time"`
+	SilenceTime      float64        `json:"silence_time"`
+	Turns            int            `json:"turns"`
+	Interruptions    int            `json:"interruptions"`
+	Speakers         []SpeakerStats `json:"speakers"`
+	LongestMonologue Monologue      `json:"longest_monologue"`
+	Silences         []Gap          `json:"silences,omitempty"`
+	Overlaps         []Overlap      `json:"overlaps,omitempty"`
+}
+
+// Speaker returns the statistics of a speaker, after merges
+func (r *Report) Speaker(speaker int) (SpeakerStats, bool) {
+	for _, stats := range r.Speakers {
+		if stats.Speaker == speaker {
+			return stats, true
+		}
+	}
+	return SpeakerStats{}, false
+}
+
+// Analyze computes conversation statistics from utterances. Word-level statistics
+// use the words attached to each utterance and fall back to splitting its text.
+func Analyze(utterances []gladia.Utterance, opts Options) *Report {
+	if opts.MinSilence <= 0 {
+		opts.MinSilence = 2
+	}
+	if opts.MinInterruption <= 0 {
+		opts.MinInterruption = 0.5
+	}
+
+	sorted := make([]gladia.Utterance, len(utterances))
+	copy(sorted, utterances)
+	for i := range sorted {
+		sorted[i].Speaker = opts.Speakers.Resolve(sorted[i].Speaker)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	report := &Report{}
+	if len(sorted) == 0 {
+		return report
+	}
+
+	type accumulator struct {
+		stats          SpeakerStats
+		confidenceSum  float64
+		confidenceSize int
+	}
+	speakers := make(map[int]*accumulator)
+	get := func(speaker int) *accumulator {
+		acc, ok := speakers[speaker]
+		if !ok {
+			acc = &accumulator{stats: SpeakerStats{Speaker: speaker, Name: opts.Speakers.Name(speaker)}}
+			speakers[speaker] = acc
+		}
+		return acc
+	}
+
+	var current Monologue
+	closeMonologue := func() {
+		current.Duration = current.End - current.Start
+		stats := &speakers[current.Speaker].stats
+		stats.LongestMonologue = max(stats.LongestMonologue, current.Duration)
+		if current.Duration > report.LongestMonologue.Duration {
+			report.LongestMonologue = current
+		}
+	}
+
+	start := sorted[0].Start
+	var end float64
+	lastSpeaker := -1
+	var active, overlapping []int
+	for i, u := range sorted {
+		acc := get(u.Speaker)
+		duration := max(u.End-u.Start, 0)
+		acc.stats.TalkTime += duration
+
+		if len(u.Words) > 0 {
+			acc.stats.Words += len(u.Words)
+			for _, w := range u.Words {
+				acc.confidenceSum += w.Confidence
+				acc.confidenceSize++
+			}
+		} else {
+			acc.stats.Words += len(strings.Fields(u.Text))
+			acc.confidenceSum += u.Confidence
+			acc.confidenceSize++
+		}
+
+		if i == 0 || u.Speaker != lastSpeaker {
+			acc.stats.Turns++
+			report.Turns++
+			if i > 0 {
+				closeMonologue()
+			}
+			current = Monologue{Speaker: u.Speaker, Start: u.Start, End: u.End}
+		} else {
+			current.End = max(current.End, u.End)
+		}
+		lastSpeaker = u.Speaker
+
+		if i > 0 {
+			if gap := u.Start - end; gap >= opts.MinSilence {
+				report.Silences = append(report.Silences, Gap{Start: end, End: u.Start, Duration: gap})
+				report.SilenceTime += gap
+			}
+		}
+
+		// active holds the earlier utterances still running, ordered by end time; those
+		// ending before this one starts cannot overlap anything later either
+		ended := 0
+		for ended < len(active) && sorted[active[ended]].End <= u.Start {
+			ended++
+		}
+		active = active[ended:]
+
+		overlapping = append(overlapping[:0], active...)
+		slices.Sort(overlapping)
+		for _, j := range overlapping {
+			previous := sorted[j]
+			if previous.Speaker == u.Speaker {
+				continue
+			}
+			overlapEnd := min(previous.End, u.End)
+			report.Overlaps = append(report.Overlaps, Overlap{
+				Start:    u.Start,
+				End:      overlapEnd,
+				Speakers: [2]int{previous.Speaker, u.Speaker},
+			})
+			if overlapEnd-u.Start >= opts.MinInterruption {
+				acc.stats.Interruptions++
+				get(previous.Speaker).stats.Interrupted++
+				report.Interruptions++
+			}
+		}
+
+		at, _ := slices.BinarySearchFunc(active, u.End, func(j int, end float64) int {
+			return cmp.Compare(sorted[j].End, end)
+		})
+		active = slices.Insert(active, at, i)
+
+		end = max(end, u.End)
+	}
+	closeMonologue()
+
+	report.Duration = end - start
+	for _, acc := range speakers {
+		report.TalkTime += acc.stats.TalkTime
+	}
+
+	for _, acc := range speakers {
+		stats := acc.stats
+		if report.TalkTime > 0 {
+			stats.TalkRatio = stats.TalkTime / report.TalkTime
+		}
+		if listen := report.TalkTime - stats.TalkTime; listen > 0 {
+			stats.TalkListenRatio = stats.TalkTime / listen
+		}
+		if stats.TalkTime > 0 {
+			stats.WordsPerMinute = float64(stats.Words) / (stats.TalkTime / 60)
+		}
+		if acc.confidenceSize > 0 {
+			stats.AverageConfidence = acc.confidenceSum / float64(acc.confidenceSize)
+		}
+		report.Speakers = append(report.Speakers, stats)
+	}
+	sort.Slice(report.Speakers, func(i, j int) bool { return report.Speakers[i].Speaker < report.Speakers[j].Speaker })
+
+	return report
+}
+
+// AnalyzeResult computes conversation statistics from the utterances of a transcription result
+func AnalyzeResult(data *gladia.TranscriptionResultData, opts Options) *Report {
+	return Analyze(data.Transcription.Utterances, opts)
+}
@@ -0,0 +1,171 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func utterance(speaker int, start, end float64, text string) gladia.Utterance {
+	return gladia.Utterance{Speaker: speaker, Start: start, End: end, Text: text, Confidence: 0.9}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAnalyze(t *testing.T) {
+	report := Analyze([]gladia.Utterance{
+		utterance(1, 5, 9, "fine thanks and you"),
+		utterance(0, 0, 4, "hello how are you"),
+		utterance(0, 8.8, 10, "good"),
+		utterance(0, 13, 15, "so about the contract"),
+		utterance(1, 14, 16, "yes"),
+	}, Options{})
+
+	if !near(report.Duration, 16) || !near(report.TalkTime, 13.2) {
+		t.Errorf("duration = %v, talk time = %v, want 16 and 13.2", report.Duration, report.TalkTime)
+	}
+	if report.Turns != 4 {
+		t.Errorf("turns = %d, want 4", report.Turns)
+	}
+	if len(report.Silences) != 1 || !near(report.Silences[0].Start, 10) || !near(report.Silences[0].Duration, 3) {
+		t.Errorf("silences = %+v, want 3 seconds from 10", report.Silences)
+	}
+	// Consecutive utterances of a speaker form one monologue, across the silence
+	if report.LongestMonologue.Speaker != 0 || !near(report.LongestMonologue.Start, 8.8) || !near(report.LongestMonologue.Duration, 6.2) {
+		t.Errorf("longest monologue = %+v, want speaker 0 from 8.8 to 15", report.LongestMonologue)
+	}
+
+	// The 0.2s overlap at 8.8 is backchannel; the 1s overlap at 14 interrupts
+	if len(report.Overlaps) != 2 || report.Interruptions != 1 {
+		t.Errorf("overlaps = %+v, interruptions = %d, want 2 overlaps and 1 interruption", report.Overlaps, report.Interruptions)
+	}
+	first, _ := report.Speaker(0)
+	second, _ := report.Speaker(1)
+	if first.Words != 9 || first.Turns != 2 || first.Interrupted != 1 {
+		t.Errorf("speaker 0 = %+v", first)
+	}
+	if second.Interruptions != 1 || !near(second.TalkTime, 6) || !near(second.TalkRatio, 6/13.2) {
+		t.Errorf("speaker 1 = %+v", second)
+	}
+	if !near(second.TalkListenRatio, 6/7.2) || !near(second.WordsPerMinute, 5/(6.0/60)) {
+		t.Errorf("speaker 1 ratios = %+v", second)
+	}
+}
+
+func TestAnalyzeSpeakerMap(t *testing.T) {
+	speakers := gladia.NewSpeakerMap()
+	speakers.SetName(0, "Alice")
+	if err := speakers.Merge(2, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Analyze([]gladia.Utterance{
+		utterance(0, 0, 2, "one"),
+		utterance(2, 2, 4, "two"),
+		utterance(1, 4, 5, "three"),
+	}, Options{Speakers: speakers})
+
+	if len(report.Speakers) != 2 || report.Speakers[0].Name != "Alice" {
+		t.Fatalf("speakers = %+v, want Alice and speaker 1", report.Speakers)
+	}
+	if alice := report.Speakers[0]; alice.Turns != 1 || !near(alice.LongestMonologue, 4) {
+		t.Errorf("Alice = %+v, want one 4 second turn after the merge", alice)
+	}
+}
+
+func TestAnalyzeEmpty(t *testing.T) {
+	report := Analyze(nil, Options{})
+	if report.Turns != 0 || len(report.Speakers) != 0 {
+		t.Errorf("report = %+v, want an empty report", report)
+	}
+}
+
+// TestOverlapSweep checks overlaps that are not with the previous utterance: a long
+// utterance keeps overlapping later ones after shorter ones in between have ended
+func TestOverlapSweep(t *testing.T) {
+	tests := []struct {
+		name       string
+		utterances []gladia.Utterance
+		want       []Overlap
+	}{
+		{
+			name: "long utterance spans several",
+			utterances: []gladia.Utterance{
+				utterance(0, 0, 10, "a long story"),
+				utterance(1, 1, 2, "mm"),
+				utterance(2, 3, 4, "right"),
+				utterance(1, 5, 12, "and then"),
+			},
+			want: []Overlap{
+				{Start: 1, End: 2, Speakers: [2]int{0, 1}},
+				{Start: 3, End: 4, Speakers: [2]int{0, 2}},
+				{Start: 5, End: 10, Speakers: [2]int{0, 1}},
+			},
+		},
+		{
+			name: "ended utterances are dropped",
+			utterances: []gladia.Utterance{
+				utterance(0, 0, 3, "first"),
+				utterance(1, 1, 2, "second"),
+				utterance(2, 3, 5, "third"),
+			},
+			want: []Overlap{
+				{Start: 1, End: 2, Speakers: [2]int{0, 1}},
+			},
+		},
+		{
+			name: "several running at once",
+			utterances: []gladia.Utterance{
+				utterance(0, 0, 6, "a"),
+				utterance(1, 1, 5, "b"),
+				utterance(2, 2, 3, "c"),
+			},
+			want: []Overlap{
+				{Start: 1, End: 5, Speakers: [2]int{0, 1}},
+				{Start: 2, End: 3, Speakers: [2]int{0, 2}},
+				{Start: 2, End: 3, Speakers: [2]int{1, 2}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Analyze(tt.utterances, Options{}).Overlaps
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("overlaps = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOverlapSweepMatchesPairwise compares the sweep with checking every pair
+func TestOverlapSweepMatchesPairwise(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	for run := range 50 {
+		utterances := make([]gladia.Utterance, 60)
+		for i := range utterances {
+			start := float64(random.IntN(600)) / 10
+			utterances[i] = utterance(random.IntN(3), start, start+float64(random.IntN(100))/10, "word")
+		}
+
+		sorted := append([]gladia.Utterance(nil), utterances...)
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+		var want []Overlap
+		for i, u := range sorted {
+			for _, previous := range sorted[:i] {
+				if previous.Speaker != u.Speaker && previous.End > u.Start {
+					want = append(want, Overlap{Start: u.Start, End: min(previous.End, u.End), Speakers: [2]int{previous.Speaker, u.Speaker}})
+				}
+			}
+		}
+
+		if got := Analyze(utterances, Options{}).Overlaps; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("run %d: overlaps = %v, want %v", run, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const flacStreamInfo = 0
+
+// probeFLAC reads the STREAMINFO block of a FLAC file into report
+func probeFLAC(r io.Reader, report *Report) error {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return fmt.Errorf("failed to read flac header: %w", err)
+	}
+
+	var block [4]byte
+	if _, err := io.ReadFull(r, block[:]); err != nil {
+		return fmt.Errorf("failed to read flac metadata: %w", err)
+	}
+	if block[0]&0x7F != flacStreamInfo {
+		return errors.New("flac file does not start with STREAMINFO")
+	}
+
+	var info [34]byte
+	if _, err := io.ReadFull(r, info[:]); err != nil {
+		return fmt.Errorf("failed to read flac STREAMINFO: %w", err)
+	}
+
+	// Bytes 10-17 pack sample rate (20 bits), channels - 1 (3 bits),
+	// bits per sample - 1 (5 bits) and total samples (36 bits)
+	packed := binary.BigEndian.Uint64(info[10:18])
+	sampleRate := int(packed >> 44)
+	channels := int(packed>>41&0x7) + 1
+	bitsPerSample := int(packed>>36&0x1F) + 1
+	totalSamples := packed & 0xFFFFFFFFF
+
+	report.SampleRate = sampleRate
+	report.NumberOfChannels = channels
+	report.BitsPerSample = bitsPerSample
+	if sampleRate > 0 {
+		report.AudioDuration = float64(totalSamples) / float64(sampleRate)
+	}
+	return nil
+}
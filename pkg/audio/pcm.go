@@ -0,0 +1,170 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// PCMFormat describes raw interleaved little-endian PCM samples
+type PCMFormat struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+}
+
+// PCMHeader returns the header of size bytes of raw PCM samples starting at offset zero
+func PCMHeader(format PCMFormat, size int64) *WAVHeader {
+	blockAlign := format.Channels * format.BitsPerSample / 8
+	return &WAVHeader{
+		AudioFormat:   wavFormatPCM,
+		Channels:      format.Channels,
+		SampleRate:    format.SampleRate,
+		ByteRate:      format.SampleRate * blockAlign,
+		BlockAlign:    blockAlign,
+		BitsPerSample: format.BitsPerSample,
+		DataSize:      size,
+	}
+}
+
+// EncodeWAVHeader writes a canonical 44-byte PCM WAV header for dataSize bytes of samples
+func EncodeWAVHeader(h *WAVHeader, dataSize int64) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(wavFormatPCM))
+	binary.Write(buf, binary.LittleEndian, uint16(h.Channels))
+	binary.Write(buf, binary.LittleEndian, uint32(h.SampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(h.ByteRate))
+	binary.Write(buf, binary.LittleEndian, uint16(h.BlockAlign))
+	binary.Write(buf, binary.LittleEndian, uint16(h.BitsPerSample))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(dataSize))
+	return buf.Bytes()
+}
+
+// Section returns a standalone WAV stream holding frames [from, to) of the audio
+func Section(r io.ReaderAt, h *WAVHeader, from, to int64) io.Reader {
+	from, to = max(from, 0), min(to, h.Frames())
+	size := max(to-from, 0) * int64(h.BlockAlign)
+	return io.MultiReader(
+		bytes.NewReader(EncodeWAVHeader(h, size)),
+		io.NewSectionReader(r, h.DataOffset+from*int64(h.BlockAlign), size),
+	)
+}
+
+// RMS returns the root mean square level of frames [from, to), between 0 and 1,
+// averaging all channels
+func RMS(r io.ReaderAt, h *WAVHeader, from, to int64) (float64, error) {
+	if !h.IsPCM() {
+		return 0, errors.New("only integer PCM audio is supported")
+	}
+	bytesPerSample := h.BitsPerSample / 8
+	if bytesPerSample < 1 || bytesPerSample > 4 {
+		return 0, fmt.Errorf("unsupported sample size: %d bits", h.BitsPerSample)
+	}
+
+	from, to = max(from, 0), min(to, h.Frames())
+	if to <= from {
+		return 0, nil
+	}
+
+	data := make([]byte, (to-from)*int64(h.BlockAlign))
+	if _, err := r.ReadAt(data, h.DataOffset+from*int64(h.BlockAlign)); err != nil && !errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf("failed to read samples: %w", err)
+	}
+
+	full := math.Ldexp(1, h.BitsPerSample-1)
+	var sum float64
+	samples := 0
+	for i := 0; i+bytesPerSample <= len(data); i += bytesPerSample {
+		v := decodeSample(data[i:i+bytesPerSample]) / full
+		sum += v * v
+		samples++
+	}
+	if samples == 0 {
+		return 0, nil
+	}
+	return math.Sqrt(sum / float64(samples)), nil
+}
+
+// decodeSample reads a little-endian sample. 8-bit samples are unsigned, wider ones signed.
+func decodeSample(b []byte) float64 {
+	switch len(b) {
+	case 1:
+		return float64(int(b[0]) - 128)
+	case 2:
+		return float64(int16(binary.LittleEndian.Uint16(b)))
+	case 3:
+		v := int32(b[0]) | int32(b[1])<<8 | int32(b[2])<<16
+		if v&0x800000 != 0 {
+			v |= ^0xFFFFFF
+		}
+		return float64(v)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(b)))
+	}
+}
+
+// Channel returns a standalone mono WAV stream holding one channel of the audio
+func Channel(r io.ReaderAt, h *WAVHeader, channel int) (io.Reader, error) {
+	if h.Channels == 0 || h.BlockAlign%h.Channels != 0 {
+		return nil, errors.New("invalid audio header")
+	}
+	if channel < 0 || channel >= h.Channels {
+		return nil, fmt.Errorf("channel %d out of range, audio has %d", channel, h.Channels)
+	}
+
+	sampleSize := h.BlockAlign / h.Channels
+	mono := *h
+	mono.Channels = 1
+	mono.BlockAlign = sampleSize
+	mono.ByteRate = h.SampleRate * sampleSize
+
+	return io.MultiReader(
+		bytes.NewReader(EncodeWAVHeader(&mono, h.Frames()*int64(sampleSize))),
+		&channelReader{
+			src:    io.NewSectionReader(r, h.DataOffset, h.Frames()*int64(h.BlockAlign)),
+			block:  h.BlockAlign,
+			offset: channel * sampleSize,
+			size:   sampleSize,
+		},
+	), nil
+}
+
+// channelReader extracts the samples of one channel from interleaved frames
+type channelReader struct {
+	src     io.Reader
+	block   int
+	offset  int
+	size    int
+	frames  []byte
+	pending []byte
+}
+
+func (c *channelReader) Read(p []byte) (int, error) {
+	if len(c.pending) == 0 {
+		if c.frames == nil {
+			c.frames = make([]byte, 4096*c.block)
+		}
+		n, err := io.ReadFull(c.src, c.frames)
+		if n < c.block {
+			if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = io.EOF
+			}
+			return 0, err
+		}
+		c.pending = c.pending[:0]
+		for i := 0; i+c.block <= n; i += c.block {
+			c.pending = append(c.pending, c.frames[i+c.offset:i+c.offset+c.size]...)
+		}
+	}
+	n := copy(p, c.pending)
+	c.pending = c.pending[n:]
+	return n, nil
+}
@@ -0,0 +1,205 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Format is an audio container format
+type Format string
+
+// Formats recognized by Detect
+const (
+	FormatUnknown Format = ""
+	FormatWAV     Format = "wav"
+	FormatMP3     Format = "mp3"
+	FormatFLAC    Format = "flac"
+	FormatOGG     Format = "ogg"
+	FormatM4A     Format = "m4a"
+	FormatWebM    Format = "webm"
+)
+
+// Errors returned by Validate
+var (
+	ErrUnsupportedFormat = errors.New("unsupported audio format")
+	ErrTooLarge          = errors.New("audio file too large")
+	ErrTooLong           = errors.New("audio too long")
+	ErrEmpty             = errors.New("audio file is empty")
+)
+
+// headerSize is how much of a file is read to detect its format
+const headerSize = 64
+
+// Report describes a local audio file in the shape of the metadata returned by UploadFile.
+// Duration, channels and sample rate are only known for WAV and FLAC files.
+type Report struct {
+	gladia.AudioMetadata
+	Format        Format `json:"format"`
+	SampleRate    int    `json:"sample_rate,omitempty"`
+	BitsPerSample int    `json:"bits_per_sample,omitempty"`
+}
+
+// Duration returns the audio duration, or zero if it is unknown
+func (r *Report) Duration() time.Duration {
+	return time.Duration(r.AudioDuration * float64(time.Second))
+}
+
+// Limits restricts which files are accepted for upload
+type Limits struct {
+	// MaxSize is the largest accepted file size in bytes; zero means no limit
+	MaxSize int64
+	// MaxDuration is the longest accepted duration; zero means no limit.
+	// Files whose duration cannot be determined are not rejected.
+	MaxDuration time.Duration
+	// Formats lists the accepted formats; empty accepts every recognized format
+	Formats []Format
+}
+
+// Validate checks a report against the limits
+func (l Limits) Validate(report *Report) error {
+	if report.Size == 0 {
+		return ErrEmpty
+	}
+	if report.Format == FormatUnknown {
+		return ErrUnsupportedFormat
+	}
+	if len(l.Formats) > 0 && !containsFormat(l.Formats, report.Format) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, report.Format)
+	}
+	if l.MaxSize > 0 && report.Size > l.MaxSize {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrTooLarge, report.Size, l.MaxSize)
+	}
+	if l.MaxDuration > 0 && report.Duration() > l.MaxDuration {
+		return fmt.Errorf("%w: %s, limit is %s", ErrTooLong, report.Duration().Round(time.Millisecond), l.MaxDuration)
+	}
+	return nil
+}
+
+// Check probes the file at path and validates it against limits
+func Check(path string, limits Limits) (*Report, error) {
+	report, err := Probe(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := limits.Validate(report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// Probe inspects the audio file at path without uploading it
+func Probe(path string) (*Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	return ProbeReader(file, info.Size(), filepath.Base(path))
+}
+
+// ProbeReader inspects size bytes of audio read from r. The name is only used for the report.
+func ProbeReader(r io.ReaderAt, size int64, name string) (*Report, error) {
+	report := &Report{
+		AudioMetadata: gladia.AudioMetadata{
+			Filename:  name,
+			Extension: strings.TrimPrefix(filepath.Ext(name), "."),
+			Size:      size,
+		},
+	}
+
+	header := make([]byte, min(size, headerSize))
+	if _, err := r.ReadAt(header, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	report.Format = Detect(header)
+
+	var err error
+	switch report.Format {
+	case FormatWAV:
+		var h *WAVHeader
+		if h, err = ReadWAVHeader(io.NewSectionReader(r, 0, size)); err == nil {
+			report.NumberOfChannels = h.Channels
+			report.SampleRate = h.SampleRate
+			report.BitsPerSample = h.BitsPerSample
+			report.AudioDuration = h.Duration()
+		}
+	case FormatFLAC:
+		err = probeFLAC(io.NewSectionReader(r, 0, size), report)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// m4aBrands are the ISO media brands of audio-only MPEG-4 files
+var m4aBrands = []string{"M4A ", "M4B ", "M4P ", "F4A ", "F4B "}
+
+// Detect identifies the container format from the first bytes of a file. MPEG-4 files
+// are only reported as M4A when their major or a compatible brand is an audio brand,
+// so videos and HEIC images are unknown.
+func Detect(header []byte) Format {
+	switch {
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WAVE")):
+		return FormatWAV
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return FormatFLAC
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return FormatOGG
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return FormatWebM
+	case len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")):
+		if isM4A(header) {
+			return FormatM4A
+		}
+		return FormatUnknown
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return FormatMP3
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0:
+		// MPEG frame sync with a non-zero layer, which excludes raw AAC
+		return FormatMP3
+	default:
+		return FormatUnknown
+	}
+}
+
+// isM4A reports whether the ftyp box at the start of header names an audio brand.
+// The box holds the major brand, a minor version and then the compatible brands.
+func isM4A(header []byte) bool {
+	end := min(len(header), int(binary.BigEndian.Uint32(header[:4])))
+	if slices.Contains(m4aBrands, string(header[8:12])) {
+		return true
+	}
+	for i := 16; i+4 <= end; i += 4 {
+		if slices.Contains(m4aBrands, string(header[i:i+4])) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFormat(formats []Format, format Format) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
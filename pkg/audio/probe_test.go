@@ -0,0 +1,187 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ftyp builds an ftyp box with a major brand and compatible brands
+func ftyp(major string, compatible ...string) []byte {
+	box := &bytes.Buffer{}
+	binary.Write(box, binary.BigEndian, uint32(16+4*len(compatible)))
+	box.WriteString("ftyp" + major)
+	binary.Write(box, binary.BigEndian, uint32(0))
+	for _, brand := range compatible {
+		box.WriteString(brand)
+	}
+	return box.Bytes()
+}
+
+// flac builds a FLAC stream header whose STREAMINFO describes the audio
+func flac(sampleRate, channels, bitsPerSample int, totalSamples uint64) []byte {
+	var info [34]byte
+	packed := uint64(sampleRate)<<44 | uint64(channels-1)<<41 | uint64(bitsPerSample-1)<<36 | totalSamples
+	binary.BigEndian.PutUint64(info[10:18], packed)
+	return append([]byte{'f', 'L', 'a', 'C', 0x80 | flacStreamInfo, 0, 0, 34}, info[:]...)
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   Format
+	}{
+		{name: "wav", header: EncodeWAVHeader(PCMHeader(PCMFormat{SampleRate: 16000, Channels: 1, BitsPerSample: 16}, 0), 0), want: FormatWAV},
+		{name: "riff without wave", header: []byte("RIFF\x00\x00\x00\x00AVI "), want: FormatUnknown},
+		{name: "flac", header: flac(44100, 2, 16, 0), want: FormatFLAC},
+		{name: "ogg", header: []byte("OggS\x00\x02"), want: FormatOGG},
+		{name: "webm", header: []byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F}, want: FormatWebM},
+		{name: "mp3 with id3", header: []byte("ID3\x04\x00"), want: FormatMP3},
+		{name: "mp3 frame", header: []byte{0xFF, 0xFB, 0x90, 0x64}, want: FormatMP3},
+		{name: "raw aac", header: []byte{0xFF, 0xF1, 0x50, 0x80}, want: FormatUnknown},
+		{name: "m4a major brand", header: ftyp("M4A ", "M4A ", "isom"), want: FormatM4A},
+		{name: "audiobook", header: ftyp("M4B "), want: FormatM4A},
+		{name: "m4a compatible brand", header: ftyp("mp42", "isom", "M4A "), want: FormatM4A},
+		{name: "mp4 video", header: ftyp("isom", "isom", "iso2", "avc1", "mp41"), want: FormatUnknown},
+		{name: "quicktime", header: ftyp("qt  ", "qt  "), want: FormatUnknown},
+		{name: "heic", header: ftyp("heic", "mif1", "heic"), want: FormatUnknown},
+		{name: "truncated ftyp", header: []byte("\x00\x00\x00\x18ftyp"), want: FormatUnknown},
+		{name: "empty", header: nil, want: FormatUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.header); got != tt.want {
+				t.Errorf("Detect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// wav builds a WAV file with the given chunks between the format and data chunks
+func wav(extra []byte, dataSize uint32, samples int) []byte {
+	h := PCMHeader(PCMFormat{SampleRate: 8000, Channels: 2, BitsPerSample: 16}, 0)
+	encoded := EncodeWAVHeader(h, int64(samples))
+	file := append([]byte{}, encoded[:36]...)
+	file = append(file, extra...)
+	file = append(file, "data"...)
+	file = binary.LittleEndian.AppendUint32(file, dataSize)
+	return append(file, make([]byte, samples)...)
+}
+
+func TestReadWAVHeader(t *testing.T) {
+	// An odd-sized chunk is followed by a padding byte
+	list := append([]byte("LIST\x03\x00\x00\x00abc"), 0)
+
+	tests := []struct {
+		name       string
+		file       []byte
+		dataOffset int64
+		dataSize   int64
+		wantErr    bool
+	}{
+		{name: "canonical", file: wav(nil, 32000, 32000), dataOffset: 44, dataSize: 32000},
+		{name: "extra chunk", file: wav(list, 32000, 32000), dataOffset: 56, dataSize: 32000},
+		{name: "streamed size", file: wav(nil, 0xFFFFFFFF, 3200), dataOffset: 44, dataSize: 3200},
+		{name: "unset size", file: wav(nil, 0, 3200), dataOffset: 44, dataSize: 3200},
+		{name: "size past the end", file: wav(nil, 32000, 3200), dataOffset: 44, dataSize: 3200},
+		{name: "not wav", file: []byte("RIFF\x00\x00\x00\x00AVI LIST"), wantErr: true},
+		{name: "data before format", file: []byte("RIFF\x00\x00\x00\x00WAVEdata\x00\x00\x00\x00"), wantErr: true},
+		{name: "truncated", file: wav(nil, 0, 0)[:40], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := ReadWAVHeader(bytes.NewReader(tt.file))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ReadWAVHeader = %+v, want an error", h)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadWAVHeader: %v", err)
+			}
+			if h.DataOffset != tt.dataOffset || h.DataSize != tt.dataSize {
+				t.Errorf("data at %d size %d, want %d size %d", h.DataOffset, h.DataSize, tt.dataOffset, tt.dataSize)
+			}
+			if h.Channels != 2 || h.SampleRate != 8000 || h.BitsPerSample != 16 || !h.IsPCM() {
+				t.Errorf("format = %+v, want 8kHz 16-bit stereo PCM", h)
+			}
+			if want := float64(tt.dataSize) / 32000; h.Duration() != want {
+				t.Errorf("Duration = %v, want %v", h.Duration(), want)
+			}
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	report, err := Probe(write("call.wav", wav(nil, 64000, 64000)))
+	if err != nil {
+		t.Fatalf("Probe wav: %v", err)
+	}
+	if report.Format != FormatWAV || report.AudioDuration != 2 || report.NumberOfChannels != 2 || report.Extension != "wav" {
+		t.Errorf("wav report = %+v", report)
+	}
+
+	report, err = Probe(write("song.flac", flac(44100, 2, 24, 441000)))
+	if err != nil {
+		t.Fatalf("Probe flac: %v", err)
+	}
+	if report.Format != FormatFLAC || report.AudioDuration != 10 || report.SampleRate != 44100 || report.BitsPerSample != 24 || report.NumberOfChannels != 2 {
+		t.Errorf("flac report = %+v", report)
+	}
+
+	if _, err := Probe(write("broken.flac", []byte("fLaC\x01\x00\x00\x22"))); err == nil {
+		t.Error("Probe accepted a FLAC file without STREAMINFO first")
+	}
+
+	report, err = Probe(write("clip.mp4", ftyp("isom", "avc1")))
+	if err != nil {
+		t.Fatalf("Probe mp4: %v", err)
+	}
+	if err := (Limits{}).Validate(report); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Validate video = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	report := &Report{Format: FormatWAV}
+	report.Size = 1000
+	report.AudioDuration = 90
+
+	tests := []struct {
+		name   string
+		limits Limits
+		want   error
+	}{
+		{name: "no limits", limits: Limits{}},
+		{name: "within limits", limits: Limits{MaxSize: 1000, MaxDuration: 2 * time.Minute, Formats: []Format{FormatWAV}}},
+		{name: "too large", limits: Limits{MaxSize: 999}, want: ErrTooLarge},
+		{name: "too long", limits: Limits{MaxDuration: time.Minute}, want: ErrTooLong},
+		{name: "format not allowed", limits: Limits{Formats: []Format{FormatMP3}}, want: ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limits.Validate(report); !errors.Is(err, tt.want) {
+				t.Errorf("Validate = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := (Limits{}).Validate(&Report{Format: FormatWAV}); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Validate empty = %v, want ErrEmpty", err)
+	}
+}
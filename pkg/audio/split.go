@@ -0,0 +1,92 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// SplitOptions configures where long audio is cut
+type SplitOptions struct {
+	// TargetLength is the preferred chunk length. Defaults to 10 minutes.
+	TargetLength time.Duration
+	// SearchWindow is how far around each target boundary a quieter cut point is searched.
+	// Defaults to 30 seconds.
+	SearchWindow time.Duration
+	// Overlap is the audio shared by consecutive chunks. Defaults to zero; negative
+	// values mean no overlap.
+	Overlap time.Duration
+	// AnalysisWindow is the length of the windows whose energy is compared. Defaults to 50ms.
+	AnalysisWindow time.Duration
+}
+
+// Chunk is a slice of audio in sample frames. Chunks cover [Start, End) including overlap
+// and own [Cut, NextCut) exclusively, so overlapping results can be deduplicated.
+type Chunk struct {
+	Index   int
+	Start   int64
+	End     int64
+	Cut     int64
+	NextCut int64
+}
+
+// Split cuts the audio into chunks close to the target length, placing every cut at the
+// quietest analysis window within the search window around the target boundary
+func Split(r io.ReaderAt, h *WAVHeader, opts SplitOptions) ([]Chunk, error) {
+	if h.SampleRate == 0 || h.BlockAlign == 0 {
+		return nil, errors.New("invalid audio header")
+	}
+	if opts.TargetLength <= 0 {
+		opts.TargetLength = 10 * time.Minute
+	}
+	if opts.SearchWindow <= 0 {
+		opts.SearchWindow = 30 * time.Second
+	}
+	if opts.AnalysisWindow <= 0 {
+		opts.AnalysisWindow = 50 * time.Millisecond
+	}
+
+	total := h.Frames()
+	target := h.frames(opts.TargetLength)
+	search := min(h.frames(opts.SearchWindow), target/2)
+	window := max(h.frames(opts.AnalysisWindow), 1)
+	overlap := h.frames(max(opts.Overlap, 0))
+
+	cuts := []int64{0}
+	for last := int64(0); total-last > target+search; {
+		cut, err := quietest(r, h, last+target-search, last+target+search, window)
+		if err != nil {
+			return nil, err
+		}
+		cuts = append(cuts, cut)
+		last = cut
+	}
+	cuts = append(cuts, total)
+
+	chunks := make([]Chunk, 0, len(cuts)-1)
+	for i := 0; i+1 < len(cuts); i++ {
+		chunks = append(chunks, Chunk{
+			Index:   i,
+			Start:   max(cuts[i]-overlap, 0),
+			End:     min(cuts[i+1]+overlap, total),
+			Cut:     cuts[i],
+			NextCut: cuts[i+1],
+		})
+	}
+	return chunks, nil
+}
+
+// quietest returns the center of the lowest-energy window between from and to
+func quietest(r io.ReaderAt, h *WAVHeader, from, to, window int64) (int64, error) {
+	best, bestLevel := (from+to)/2, -1.0
+	for start := from; start+window <= to; start += window {
+		level, err := RMS(r, h, start, start+window)
+		if err != nil {
+			return 0, err
+		}
+		if bestLevel < 0 || level < bestLevel {
+			best, bestLevel = start+window/2, level
+		}
+	}
+	return best, nil
+}
@@ -0,0 +1,131 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WAV format codes
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+)
+
+// WAVHeader describes the layout of a WAV file
+type WAVHeader struct {
+	AudioFormat   int
+	Channels      int
+	SampleRate    int
+	ByteRate      int
+	BlockAlign    int
+	BitsPerSample int
+	// DataOffset is the position of the first sample in the file
+	DataOffset int64
+	// DataSize is the number of bytes of sample data
+	DataSize int64
+}
+
+// Duration returns the length of the audio in seconds
+func (h *WAVHeader) Duration() float64 {
+	if h.ByteRate == 0 {
+		return 0
+	}
+	return float64(h.DataSize) / float64(h.ByteRate)
+}
+
+// Frames returns the number of sample frames, one sample per channel each
+func (h *WAVHeader) Frames() int64 {
+	if h.BlockAlign == 0 {
+		return 0
+	}
+	return h.DataSize / int64(h.BlockAlign)
+}
+
+// Seconds converts a frame position to seconds
+func (h *WAVHeader) Seconds(frame int64) float64 {
+	if h.SampleRate == 0 {
+		return 0
+	}
+	return float64(frame) / float64(h.SampleRate)
+}
+
+func (h *WAVHeader) frames(d time.Duration) int64 {
+	return int64(d.Seconds() * float64(h.SampleRate))
+}
+
+// IsPCM reports whether samples are uncompressed integers
+func (h *WAVHeader) IsPCM() bool {
+	return h.AudioFormat == wavFormatPCM || h.AudioFormat == wavFormatExtensible
+}
+
+// ReadWAVHeader parses the RIFF chunks of a WAV file up to the start of its sample data
+func ReadWAVHeader(r io.ReadSeeker) (*WAVHeader, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wav header: %w", err)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read wav header: %w", err)
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("failed to read wav header: %w", err)
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return nil, errors.New("not a wav file")
+	}
+
+	header := &WAVHeader{}
+	offset := int64(12)
+	haveFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("failed to read wav chunk: %w", err)
+		}
+		id := string(chunk[:4])
+		chunkSize := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		offset += 8
+
+		switch id {
+		case "fmt ":
+			if chunkSize < 16 {
+				return nil, errors.New("invalid wav format chunk")
+			}
+			var format [16]byte
+			if _, err := io.ReadFull(r, format[:]); err != nil {
+				return nil, fmt.Errorf("failed to read wav format: %w", err)
+			}
+			header.AudioFormat = int(binary.LittleEndian.Uint16(format[0:]))
+			header.Channels = int(binary.LittleEndian.Uint16(format[2:]))
+			header.SampleRate = int(binary.LittleEndian.Uint32(format[4:]))
+			header.ByteRate = int(binary.LittleEndian.Uint32(format[8:]))
+			header.BlockAlign = int(binary.LittleEndian.Uint16(format[12:]))
+			header.BitsPerSample = int(binary.LittleEndian.Uint16(format[14:]))
+			haveFormat = true
+			if _, err := r.Seek(offset+chunkSize+chunkSize%2, io.SeekStart); err != nil {
+				return nil, fmt.Errorf("failed to skip wav chunk: %w", err)
+			}
+		case "data":
+			if !haveFormat {
+				return nil, errors.New("wav data chunk before format chunk")
+			}
+			header.DataOffset = offset
+			// Streamed files may leave the size unset or larger than the file
+			header.DataSize = min(chunkSize, size-offset)
+			if chunkSize == 0 || chunkSize == 0xFFFFFFFF {
+				header.DataSize = size - offset
+			}
+			return header, nil
+		default:
+			if _, err := r.Seek(chunkSize+chunkSize%2, io.SeekCurrent); err != nil {
+				return nil, fmt.Errorf("failed to skip wav chunk: %w", err)
+			}
+		}
+		offset += chunkSize + chunkSize%2
+	}
+}
@@ -0,0 +1,240 @@
+package batch
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/export"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Transcriber is the part of gladia.Client used to run a batch
+type Transcriber interface {
+	UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error)
+	TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error)
+	WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error)
+}
+
+// Options configures a batch run
+type Options struct {
+	// Concurrency is the number of rows transcribed at once. Defaults to 4.
+	Concurrency int
+	// OutputDir receives the outputs of every row; empty writes no outputs
+	OutputDir string
+	// Formats lists the outputs written for every row, see export.WriteFiles
+	Formats []string
+	// WaitOptions are passed to WaitForTranscription
+	WaitOptions []gladia.WaitOption
+	// OnResult is called as rows finish, possibly from several goroutines
+	OnResult func(Result)
+}
+
+// Result is the outcome of one manifest row
+type Result struct {
+	Row             int                   `json:"row"`
+	Source          string                `json:"source"`
+	TranscriptionID string                `json:"transcription_id,omitempty"`
+	Status          string                `json:"status"`
+	BillingTime     float64               `json:"billing_time,omitempty"`
+	Outputs         []string              `json:"outputs,omitempty"`
+	CustomMetadata  gladia.CustomMetadata `json:"custom_metadata,omitempty"`
+	Error           string                `json:"error,omitempty"`
+}
+
+// Run transcribes every row of the manifest and returns one result per row, in manifest
+// order. Failed rows are reported in their result rather than stopping the batch.
+func Run(ctx context.Context, client Transcriber, manifest *Manifest, opts Options) []Result {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	names := outputNames(manifest.Rows)
+	results := make([]Result, len(manifest.Rows))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(opts.Concurrency, len(manifest.Rows)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				row := &manifest.Rows[i]
+				result := Result{Row: i + 1, Source: row.Source(), CustomMetadata: row.CustomMetadata}
+				if err := ctx.Err(); err != nil {
+					result.Status = gladia.StatusError
+					result.Error = err.Error()
+				} else {
+					result = runRow(ctx, client, manifest.Dir, row, names[i], result, opts)
+				}
+
+				results[i] = result
+				if opts.OnResult != nil {
+					opts.OnResult(result)
+				}
+			}
+		}()
+	}
+	for i := range manifest.Rows {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+func runRow(ctx context.Context, client Transcriber, dir string, row *Row, name string, result Result, opts Options) Result {
+	fail := func(err error) Result {
+		result.Status = gladia.StatusError
+		result.Error = err.Error()
+		return result
+	}
+
+	req := row.Request()
+	if row.File != "" {
+		file := row.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return fail(fmt.Errorf("failed to open file: %w", err))
+		}
+		upload, err := client.UploadReader(ctx, filepath.Base(file), f)
+		f.Close()
+		if err != nil {
+			return fail(err)
+		}
+		req.AudioURL = upload.AudioURL
+	}
+
+	job, err := client.TranscribeWithRequest(ctx, &req)
+	if err != nil {
+		return fail(err)
+	}
+	result.TranscriptionID = job.ID
+
+	completed, err := client.WaitForTranscription(ctx, job.ID, opts.WaitOptions...)
+	if err != nil {
+		return fail(err)
+	}
+	result.Status = completed.Status
+	result.BillingTime = completed.Result.Metadata.BillingTime
+
+	if opts.OutputDir != "" && len(opts.Formats) > 0 {
+		outputs, err := export.WriteFiles(opts.OutputDir, name, completed, opts.Formats, export.Options{Title: name})
+		result.Outputs = outputs
+		if err != nil {
+			return fail(err)
+		}
+	}
+	return result
+}
+
+// outputNames names the outputs of each row after its file or URL, numbering duplicates
+func outputNames(rows []Row) []string {
+	names := make([]string, len(rows))
+	counts := make(map[string]int)
+	for i := range rows {
+		base := rows[i].File
+		if base == "" {
+			base = rows[i].AudioURL
+			if u, err := url.Parse(base); err == nil {
+				base = u.Path
+			}
+			base = path.Base(base)
+		} else {
+			base = filepath.Base(base)
+		}
+		base = strings.TrimSuffix(base, path.Ext(base))
+		if base == "" || base == "." || base == "/" {
+			base = "row"
+		}
+		names[i] = base
+		counts[base]++
+	}
+
+	seen := make(map[string]int)
+	for i, name := range names {
+		if counts[name] > 1 {
+			seen[name]++
+			names[i] = fmt.Sprintf("%s-%d", name, seen[name])
+		}
+	}
+	return names
+}
+
+// WriteResults writes a results manifest as CSV or JSON, chosen by the file extension
+func WriteResults(path string, results []Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create results manifest: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = WriteJSON(file, results)
+	default:
+		err = WriteCSV(file, results)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// WriteJSON writes results as a JSON array
+func WriteJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes results as CSV, with outputs separated by semicolons and custom
+// metadata as JSON
+func WriteCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"row", "source", "transcription_id", "status", "billing_time", "outputs", "custom_metadata", "error"}); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	for _, r := range results {
+		metadata := ""
+		if len(r.CustomMetadata) > 0 {
+			data, err := json.Marshal(r.CustomMetadata)
+			if err != nil {
+				return fmt.Errorf("failed to encode custom metadata: %w", err)
+			}
+			metadata = string(data)
+		}
+		err := writer.Write([]string{
+			strconv.Itoa(r.Row),
+			r.Source,
+			r.TranscriptionID,
+			r.Status,
+			strconv.FormatFloat(r.BillingTime, 'f', -1, 64),
+			strings.Join(r.Outputs, ";"),
+			metadata,
+			r.Error,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladiatest"
+)
+
+// countingClient records how many rows are being transcribed at once
+type countingClient struct {
+	*gladia.Client
+
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *countingClient) TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+	return c.Client.TranscribeWithRequest(ctx, req)
+}
+
+func (c *countingClient) WaitForTranscription(ctx context.Context, id string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error) {
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+	return c.Client.WaitForTranscription(ctx, id, opts...)
+}
+
+func TestRun(t *testing.T) {
+	server := gladiatest.NewServer(gladiatest.WithLifecycle(gladiatest.Lifecycle{ProcessingFor: 20 * time.Millisecond}))
+	defer server.Close()
+	client := &countingClient{Client: server.Client()}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "call.wav"), []byte("audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := &Manifest{Dir: dir, Rows: []Row{
+		{File: "call.wav", CustomMetadata: gladia.CustomMetadata{"customer": "acme"}},
+		{File: "missing.wav"},
+	}}
+	for i := range 8 {
+		manifest.Rows = append(manifest.Rows, Row{AudioURL: fmt.Sprintf("https://example.com/%d/call.wav", i)})
+	}
+
+	output := t.TempDir()
+	var reported sync.Map
+	results := Run(context.Background(), client, manifest, Options{
+		Concurrency: 3,
+		OutputDir:   output,
+		Formats:     []string{"txt"},
+		WaitOptions: []gladia.WaitOption{gladia.WithPollInterval(5 * time.Millisecond)},
+		OnResult:    func(r Result) { reported.Store(r.Row, r) },
+	})
+
+	if len(results) != len(manifest.Rows) {
+		t.Fatalf("got %d results for %d rows", len(results), len(manifest.Rows))
+	}
+	for i, result := range results {
+		if result.Row != i+1 {
+			t.Errorf("result %d is for row %d", i, result.Row)
+		}
+		if _, ok := reported.Load(result.Row); !ok {
+			t.Errorf("row %d was not reported", result.Row)
+		}
+	}
+	if results[0].Status != gladia.StatusDone || results[0].TranscriptionID == "" || results[0].BillingTime == 0 {
+		t.Errorf("file row = %+v, want a billed done result", results[0])
+	}
+	if results[0].CustomMetadata["customer"] != "acme" {
+		t.Errorf("file row metadata = %v", results[0].CustomMetadata)
+	}
+	if results[1].Status != gladia.StatusError || results[1].Error == "" {
+		t.Errorf("missing file row = %+v, want an error", results[1])
+	}
+
+	// Rows named after the same file get numbered outputs
+	want := filepath.Join(output, "call-4.txt")
+	if got := results[4].Outputs; len(got) != 1 || got[0] != want {
+		t.Errorf("outputs = %v, want [%s]", got, want)
+	}
+	if client.peak > 3 {
+		t.Errorf("%d rows transcribed at once, want at most 3", client.peak)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	server := gladiatest.NewServer()
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	manifest := &Manifest{Rows: []Row{{AudioURL: "https://example.com/a.wav"}, {AudioURL: "https://example.com/b.wav"}}}
+	results := Run(ctx, server.Client(), manifest, Options{})
+	for _, result := range results {
+		if result.Status != gladia.StatusError || result.Error != context.Canceled.Error() {
+			t.Errorf("row %d = %+v, want cancelled", result.Row, result)
+		}
+	}
+	if jobs, _ := server.Client().ListTranscriptions(context.Background(), nil); len(jobs.Items) != 0 {
+		t.Errorf("%d transcriptions started after cancellation", len(jobs.Items))
+	}
+}
@@ -0,0 +1,214 @@
+package batch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// metadataPrefix marks CSV columns holding custom metadata, e.g. metadata.customer
+const metadataPrefix = "metadata."
+
+// Row is one recording to transcribe
+type Row struct {
+	// File is a local audio file, relative to the manifest directory unless absolute
+	File string `json:"file,omitempty"`
+	// AudioURL is audio already reachable by Gladia; used when File is empty
+	AudioURL        string                `json:"audio_url,omitempty"`
+	Language        string                `json:"language,omitempty"`
+	Diarization     bool                  `json:"diarization,omitempty"`
+	Translation     bool                  `json:"translation,omitempty"`
+	TargetLanguages []string              `json:"target_languages,omitempty"`
+	CustomMetadata  gladia.CustomMetadata `json:"custom_metadata,omitempty"`
+}
+
+// Source returns the file or URL of the row
+func (r *Row) Source() string {
+	if r.File != "" {
+		return r.File
+	}
+	return r.AudioURL
+}
+
+// Request maps the row onto a transcription request. Rows with a file leave the audio URL empty.
+func (r *Row) Request() gladia.TranscriptionRequest {
+	req := gladia.TranscriptionRequest{
+		AudioURL:    r.AudioURL,
+		Language:    r.Language,
+		Diarization: r.Diarization,
+		Translation: r.Translation || len(r.TargetLanguages) > 0,
+	}
+	if req.Translation {
+		req.TranslationConfig = &gladia.TranslationConfig{TargetLanguages: r.TargetLanguages}
+	}
+	for key, value := range r.CustomMetadata {
+		req.SetMetadata(key, value)
+	}
+	return req
+}
+
+// Manifest is a batch of recordings
+type Manifest struct {
+	// Dir resolves relative file paths, usually the directory of the manifest file
+	Dir  string
+	Rows []Row
+}
+
+// ReadManifest reads a CSV or JSON manifest, chosen by the file extension
+func ReadManifest(path string) (*Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer file.Close()
+
+	var rows []Row
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = ParseCSV(file)
+	case ".json":
+		rows, err = ParseJSON(file)
+	default:
+		return nil, fmt.Errorf("unsupported manifest format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Manifest{Dir: filepath.Dir(path), Rows: rows}, nil
+}
+
+// ParseJSON reads a manifest holding a JSON array of rows. Unknown fields are an error,
+// so a misspelled field does not silently drop its value.
+func ParseJSON(r io.Reader) ([]Row, error) {
+	var rows []Row
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	for i := range rows {
+		if err := validate(&rows[i]); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+	return rows, nil
+}
+
+// ParseCSV reads a manifest with a header row. Recognized columns are file, audio_url,
+// language, diarization, translation and target_languages (separated by spaces, commas
+// or semicolons). Columns named metadata.<key> become custom metadata; any other column
+// is an error, so a misspelled header does not silently drop its values.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+		if !knownColumn(header[i]) {
+			return nil, fmt.Errorf("unknown manifest column %q", header[i])
+		}
+	}
+
+	var rows []Row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+
+		row := Row{}
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if err := row.set(header[i], value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if row.File == "" && row.AudioURL == "" && len(row.CustomMetadata) == 0 {
+			continue
+		}
+		if err := validate(&row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func (r *Row) set(column, value string) error {
+	var err error
+	switch {
+	case column == "file" || column == "path":
+		r.File = value
+	case column == "audio_url" || column == "url":
+		r.AudioURL = value
+	case column == "language":
+		r.Language = value
+	case column == "diarization":
+		r.Diarization, err = parseFlag(value)
+	case column == "translation":
+		r.Translation, err = parseFlag(value)
+	case column == "target_languages":
+		r.TargetLanguages = strings.FieldsFunc(value, func(c rune) bool {
+			return c == ',' || c == ';' || c == ' '
+		})
+	case strings.HasPrefix(column, metadataPrefix):
+		if value == "" {
+			return nil
+		}
+		r.CustomMetadata.Set(strings.TrimPrefix(column, metadataPrefix), value)
+	}
+	if err != nil {
+		return fmt.Errorf("column %s: %w", column, err)
+	}
+	return nil
+}
+
+// knownColumn reports whether set understands column. Blank headers, such as the
+// trailing columns some spreadsheets export, are allowed and ignored.
+func knownColumn(column string) bool {
+	switch column {
+	case "", "file", "path", "audio_url", "url", "language", "diarization", "translation", "target_languages":
+		return true
+	}
+	return strings.HasPrefix(column, metadataPrefix) && len(column) > len(metadataPrefix)
+}
+
+// parseFlag accepts the yes/no spellings found in hand-written spreadsheets
+func parseFlag(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no", "n", "off":
+		return false, nil
+	case "1", "true", "yes", "y", "x", "on":
+		return true, nil
+	default:
+		return strconv.ParseBool(value)
+	}
+}
+
+func validate(r *Row) error {
+	if r.File == "" && r.AudioURL == "" {
+		return errors.New("row needs a file or an audio_url")
+	}
+	if r.File != "" && r.AudioURL != "" {
+		return errors.New("row has both a file and an audio_url")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package batch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func TestParseCSV(t *testing.T) {
+	manifest := `file,audio_url,language,diarization,translation,target_languages,metadata.customer,
+calls/a.wav,,en,yes,,fr;de,acme,
+,https://example.com/b.mp3,,0,x,,,
+
+,,,,,,,
+`
+	rows, err := ParseCSV(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("ParseCSV: %v", err)
+	}
+	want := []Row{
+		{File: "calls/a.wav", Language: "en", Diarization: true, TargetLanguages: []string{"fr", "de"}, CustomMetadata: gladia.CustomMetadata{"customer": "acme"}},
+		{AudioURL: "https://example.com/b.mp3", Translation: true, TargetLanguages: []string{}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %+v, want %+v", rows, want)
+	}
+
+	req := rows[0].Request()
+	if !req.Translation || req.TranslationConfig == nil || len(req.TranslationConfig.TargetLanguages) != 2 {
+		t.Errorf("target languages did not turn on translation: %+v", req)
+	}
+	if req.CustomMetadata["customer"] != "acme" {
+		t.Errorf("request metadata = %v", req.CustomMetadata)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		parse func(string) ([]Row, error)
+		input string
+		want  string
+	}{
+		{name: "csv unknown column", parse: csvRows, input: "file,langauge\na.wav,en\n", want: `unknown manifest column "langauge"`},
+		{name: "csv bad flag", parse: csvRows, input: "file,diarization\na.wav,maybe\n", want: "line 2: column diarization"},
+		{name: "csv file and url", parse: csvRows, input: "file,url\na.wav,https://example.com/a.wav\n", want: "both a file and an audio_url"},
+		{name: "csv metadata only", parse: csvRows, input: "file,metadata.customer\n,acme\n", want: "needs a file or an audio_url"},
+		{name: "json unknown field", parse: jsonRows, input: `[{"file": "a.wav", "langauge": "en"}]`, want: `unknown field "langauge"`},
+		{name: "json missing source", parse: jsonRows, input: `[{"file": "a.wav"}, {"language": "en"}]`, want: "row 2: row needs a file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.parse(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	rows, err := jsonRows(`[{"audio_url": "https://example.com/a.wav", "target_languages": ["fr"], "custom_metadata": {"order": 7}}]`)
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+	if len(rows) != 1 || rows[0].AudioURL != "https://example.com/a.wav" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if order, ok := rows[0].CustomMetadata.Int("order"); !ok || order != 7 {
+		t.Errorf("order = %v, %v", order, ok)
+	}
+}
+
+func csvRows(input string) ([]Row, error) {
+	return ParseCSV(strings.NewReader(input))
+}
+
+func jsonRows(input string) ([]Row, error) {
+	return ParseJSON(strings.NewReader(input))
+}
@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Transcriber is the part of gladia.Client used on a cache miss
+type Transcriber interface {
+	UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error)
+	TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error)
+	WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error)
+}
+
+// Cache serves completed transcriptions of audio already transcribed with the same options
+type Cache struct {
+	client      Transcriber
+	store       Store
+	ttl         time.Duration
+	waitOptions []gladia.WaitOption
+	now         func() time.Time
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithTTL sets how long results are served after being stored. Zero keeps them forever.
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// WithWaitOptions sets the options passed to WaitForTranscription on a miss
+func WithWaitOptions(opts ...gladia.WaitOption) Option {
+	return func(c *Cache) {
+		c.waitOptions = opts
+	}
+}
+
+// New creates a cache in front of client backed by store
+func New(client Transcriber, store Store, opts ...Option) *Cache {
+	c := &Cache{
+		client: client,
+		store:  store,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Stats counts lookups served from the store and from the API
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+// Stats returns the lookups counted since the cache was created
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// TranscribeFile returns the cached result for the file and request, transcribing it on a miss
+func (c *Cache) TranscribeFile(ctx context.Context, path string, req *gladia.TranscriptionRequest) (*gladia.CompletedTranscriptionResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return c.Transcribe(ctx, filepath.Base(path), file, req)
+}
+
+// Transcribe returns the cached result for the audio and request, uploading and
+// transcribing the audio on a miss. The audio is read twice on a miss.
+//
+// A hit is answered for this request: it carries the request's custom metadata instead
+// of those of the job it was cached from, and a zero billing time since nothing was
+// billed, so usage trackers do not charge it twice. Its ID still names the cached job.
+func (c *Cache) Transcribe(ctx context.Context, filename string, audio io.ReadSeeker, req *gladia.TranscriptionRequest) (*gladia.CompletedTranscriptionResult, error) {
+	if _, err := audio.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind audio: %w", err)
+	}
+	key, err := Key(audio, req)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok, err := c.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if ok && !entry.Expired(c.now()) {
+		c.hits.Add(1)
+		result := entry.Result
+		result.CustomMetadata = maps.Clone(req.CustomMetadata)
+		result.Result.Metadata.BillingTime = 0
+		return result, nil
+	}
+	c.misses.Add(1)
+
+	if _, err := audio.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind audio: %w", err)
+	}
+	upload, err := c.client.UploadReader(ctx, filename, audio)
+	if err != nil {
+		return nil, err
+	}
+
+	submit := *req
+	submit.AudioURL = upload.AudioURL
+	job, err := c.client.TranscribeWithRequest(ctx, &submit)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.client.WaitForTranscription(ctx, job.ID, c.waitOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &Entry{Result: result}
+	if c.ttl > 0 {
+		entry.Expires = c.now().Add(c.ttl)
+	}
+	if err := c.store.Put(key, entry); err != nil {
+		return result, fmt.Errorf("failed to cache result: %w", err)
+	}
+	return result, nil
+}
+
+// Invalidate removes the cached result for the audio and request
+func (c *Cache) Invalidate(audio io.Reader, req *gladia.TranscriptionRequest) error {
+	key, err := Key(audio, req)
+	if err != nil {
+		return err
+	}
+	return c.store.Delete(key)
+}
+
+// Key hashes the audio bytes together with the request options that affect the result.
+// The audio URL, callback settings and custom metadata are ignored.
+func Key(audio io.Reader, req *gladia.TranscriptionRequest) (string, error) {
+	normalized := *req
+	normalized.AudioURL = ""
+	normalized.Callback = false
+	normalized.CallbackURL = ""
+	normalized.CallbackConfig = nil
+	normalized.CustomMetadata = nil
+
+	options, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	hash := sha256.New()
+	hash.Write(options)
+	if _, err := io.Copy(hash, audio); err != nil {
+		return "", fmt.Errorf("failed to hash audio: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladiatest"
+)
+
+func TestCachedResultsAreCopies(t *testing.T) {
+	server := gladiatest.NewServer()
+	defer server.Close()
+	cache := New(server.Client(), NewLRU(10))
+	ctx := context.Background()
+	req := &gladia.TranscriptionRequest{}
+
+	first, err := cache.Transcribe(ctx, "a.wav", strings.NewReader("audio"), req)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	first.Result.Transcription.FullTranscript = "modified by the caller"
+
+	second, err := cache.Transcribe(ctx, "a.wav", strings.NewReader("audio"), req)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	second.Result.Transcription.Utterances[0].Text = "modified again"
+
+	third, err := cache.Transcribe(ctx, "a.wav", strings.NewReader("audio"), req)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if got := third.Result.Transcription.FullTranscript; got != "Hello from the test server." {
+		t.Errorf("cached transcript = %q, changed through a returned result", got)
+	}
+	if got := third.Result.Transcription.Utterances[0].Text; got != "Hello from the test server." {
+		t.Errorf("cached utterance = %q, changed through a returned result", got)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("stats = %+v, want 2 hits and 1 miss", stats)
+	}
+}
+
+func TestCorruptDirEntryIsAMiss(t *testing.T) {
+	server := gladiatest.NewServer()
+	defer server.Close()
+	dir, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := New(server.Client(), dir)
+	ctx := context.Background()
+	req := &gladia.TranscriptionRequest{}
+
+	key, err := Key(strings.NewReader("audio"), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir.path, key+".json")
+	if err := os.WriteFile(path, []byte(`{"result": {"id": "trunc`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := cache.Transcribe(ctx, "a.wav", strings.NewReader("audio"), req)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if result.Status != gladia.StatusDone {
+		t.Errorf("status = %q, want done", result.Status)
+	}
+	if stats := cache.Stats(); stats.Misses != 1 {
+		t.Errorf("stats = %+v, want the corrupt entry counted as a miss", stats)
+	}
+	if _, ok, err := dir.Get(key); err != nil || !ok {
+		t.Errorf("entry was not replaced after the miss: ok=%v err=%v", ok, err)
+	}
+}
+
+func TestHitIsAnsweredForTheRequest(t *testing.T) {
+	server := gladiatest.NewServer()
+	defer server.Close()
+	cache := New(server.Client(), NewLRU(10))
+	ctx := context.Background()
+
+	first, err := cache.Transcribe(ctx, "a.wav", strings.NewReader("audio"), &gladia.TranscriptionRequest{
+		CustomMetadata: gladia.CustomMetadata{"tenant": "a"},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if first.Result.Metadata.BillingTime == 0 {
+		t.Fatal("miss has no billing time")
+	}
+
+	second, err := cache.Transcribe(ctx, "a.wav", strings.NewReader("audio"), &gladia.TranscriptionRequest{
+		CustomMetadata: gladia.CustomMetadata{"tenant": "b"},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 {
+		t.Fatalf("stats = %+v, want the second request served from the cache", stats)
+	}
+	if got := second.CustomMetadata["tenant"]; got != "b" {
+		t.Errorf("hit tenant = %v, want the request's", got)
+	}
+	if got := second.Result.Metadata.BillingTime; got != 0 {
+		t.Errorf("hit billing time = %v, want 0", got)
+	}
+	if got := second.Result.Transcription.FullTranscript; got != first.Result.Transcription.FullTranscript {
+		t.Errorf("hit transcript = %q, want %q", got, first.Result.Transcription.FullTranscript)
+	}
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Entry is a cached result and when it stops being served
+type Entry struct {
+	Result *gladia.CompletedTranscriptionResult `json:"result"`
+	// Expires is the expiry time; zero means the entry never expires
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// Expired reports whether the entry is past its expiry time
+func (e *Entry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// Store persists cache entries by key. Implementations must not share the results they
+// hold with callers, who may modify what Get returns or what they passed to Put.
+type Store interface {
+	// Get returns the entry stored under key, or false if there is none
+	Get(key string) (*Entry, bool, error)
+	Put(key string, entry *Entry) error
+	Delete(key string) error
+}
+
+// LRU is an in-memory Store that evicts the least recently used entry when full.
+// Entries are kept encoded, so every Get returns a copy that callers may modify freely.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type lruItem struct {
+	key  string
+	data []byte
+}
+
+// NewLRU creates an in-memory store holding at most capacity entries.
+// A capacity of zero or less means no limit.
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry stored under key and marks it as recently used
+func (l *LRU) Get(key string) (*Entry, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	l.order.MoveToFront(element)
+
+	entry := &Entry{}
+	if err := json.Unmarshal(element.Value.(*lruItem).data, entry); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	return entry, true, nil
+}
+
+// Put stores an entry, evicting the least recently used one if the store is full
+func (l *LRU) Put(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		element.Value.(*lruItem).data = data
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.entries[key] = l.order.PushFront(&lruItem{key: key, data: data})
+	if l.capacity > 0 && l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
+
+// Delete removes the entry stored under key
+func (l *LRU) Delete(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.entries[key]; ok {
+		l.order.Remove(element)
+		delete(l.entries, key)
+	}
+	return nil
+}
+
+// Len returns the number of stored entries
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// Dir is a Store keeping one JSON file per entry in a directory
+type Dir struct {
+	path string
+}
+
+// NewDir creates a store in the directory at path, creating it if needed
+func NewDir(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Dir{path: path}, nil
+}
+
+// Get reads the entry stored under key. A corrupt entry is deleted and reported as missing.
+func (d *Dir) Get(key string) (*Entry, bool, error) {
+	data, err := os.ReadFile(d.file(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	entry := &Entry{}
+	if err := json.Unmarshal(data, entry); err != nil || entry.Result == nil {
+		return nil, false, d.Delete(key)
+	}
+	return entry, true, nil
+}
+
+// Put writes an entry, replacing the file atomically
+func (d *Dir) Put(key string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(d.path, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.file(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the entry stored under key
+func (d *Dir) Delete(key string) error {
+	if err := os.Remove(d.file(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes expired entries and returns how many were removed
+func (d *Dir) Prune(now time.Time) (int, error) {
+	files, err := filepath.Glob(filepath.Join(d.path, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+
+	removed := 0
+	for _, file := range files {
+		key := filepath.Base(file[:len(file)-len(".json")])
+		entry, ok, err := d.Get(key)
+		if err != nil || !ok || !entry.Expired(now) {
+			continue
+		}
+		if err := d.Delete(key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (d *Dir) file(key string) string {
+	return filepath.Join(d.path, key+".json")
+}
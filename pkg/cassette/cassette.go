@@ -0,0 +1,414 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const gladiaHeaderKey = "x-gladia-key"
+
+// Redacted replaces scrubbed secrets in recorded interactions
+const Redacted = "REDACTED"
+
+// Mode selects whether a Recorder talks to the network or serves recorded interactions
+type Mode int
+
+const (
+	// ModeReplay serves interactions from the cassette without touching the network
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to the real API and records them
+	ModeRecord
+)
+
+// Matcher selects how replayed requests are paired with recorded interactions
+type Matcher int
+
+const (
+	// MatchInOrder serves interactions in the order they were recorded, failing a request
+	// whose method or path differs from the next interaction
+	MatchInOrder Matcher = iota
+	// MatchMethodPath serves the first unused interaction with the same method and path
+	MatchMethodPath
+)
+
+// Cassette is the content of a fixture file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request
+type Request struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Status     string      `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an HTTPDoer that records real interactions to a cassette file or replays them
+type Recorder struct {
+	path    string
+	mode    Mode
+	matcher Matcher
+	next    gladia.HTTPDoer
+	scrub   []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	position int
+}
+
+// Option configures a Recorder
+type Option func(*Recorder)
+
+// WithHTTPClient sets the HTTP client used to reach the real API in record mode
+func WithHTTPClient(httpClient gladia.HTTPDoer) Option {
+	return func(r *Recorder) {
+		r.next = httpClient
+	}
+}
+
+// WithMatcher sets how requests are paired with interactions in replay mode
+func WithMatcher(matcher Matcher) Option {
+	return func(r *Recorder) {
+		r.matcher = matcher
+	}
+}
+
+// WithScrubbedFields adds JSON field names whose string values are redacted when recording
+func WithScrubbedFields(fields ...string) Option {
+	return func(r *Recorder) {
+		r.scrub = append(r.scrub, fields...)
+	}
+}
+
+// New creates a Recorder backed by the cassette file at path.
+// In replay mode the file must already exist.
+func New(path string, mode Mode, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:  path,
+		mode:  mode,
+		next:  http.DefaultClient,
+		scrub: []string{"audio_url", "source", "result_url", "callback_url"},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to decode cassette: %w", err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+
+	return r, nil
+}
+
+// Do records or replays req depending on the recorder mode
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeRecord {
+		return r.record(req)
+	}
+	return r.replay(req)
+}
+
+// Save writes the recorded interactions to the cassette file
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+func (r *Recorder) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := r.next.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	secrets := r.secrets(req.Header, reqBody, respBody)
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.RawQuery,
+			Header: r.scrubHeader(req.Header),
+			Body:   r.scrubBody(req.Header.Get("Content-Type"), reqBody, secrets),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     scrubResponseHeader(resp.Header),
+			Body:       r.scrubBody(resp.Header.Get("Content-Type"), respBody, secrets),
+		},
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index := -1
+	switch r.matcher {
+	case MatchMethodPath:
+		for i, interaction := range r.cassette.Interactions {
+			if !r.used[i] && interaction.Request.Method == req.Method && interaction.Request.Path == req.URL.Path {
+				index = i
+				break
+			}
+		}
+	default:
+		if r.position < len(r.cassette.Interactions) {
+			recorded := r.cassette.Interactions[r.position].Request
+			if recorded.Method != req.Method || recorded.Path != req.URL.Path {
+				return nil, fmt.Errorf("request %s %s does not match recorded interaction %d: %s %s",
+					req.Method, req.URL.Path, r.position, recorded.Method, recorded.Path)
+			}
+			index = r.position
+			r.position++
+		}
+	}
+
+	if index < 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, req.URL.Path)
+	}
+	r.used[index] = true
+
+	recorded := r.cassette.Interactions[index].Response
+	return &http.Response{
+		StatusCode:    recorded.StatusCode,
+		Status:        recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) scrubHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	if scrubbed.Get(gladiaHeaderKey) != "" {
+		scrubbed.Set(gladiaHeaderKey, Redacted)
+	}
+	return scrubbed
+}
+
+// scrubResponseHeader drops headers that no longer hold once the body is scrubbed
+func scrubResponseHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+	scrubbed.Del("Content-Length")
+	scrubbed.Del("Date")
+	return scrubbed
+}
+
+// scrubBody redacts configured fields of JSON bodies and the interaction secrets found
+// in text bodies. JSON bodies are otherwise kept byte for byte, and binary bodies, such
+// as uploaded audio, are not stored.
+func (r *Recorder) scrubBody(contentType string, body []byte, secrets []string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.HasPrefix(contentType, "text/") {
+		text := string(body)
+		for _, secret := range secrets {
+			text = strings.ReplaceAll(text, secret, Redacted)
+		}
+		return text
+	}
+	if !strings.HasPrefix(contentType, "application/json") {
+		return ""
+	}
+
+	spans, err := r.scrubbedSpans(body)
+	if err != nil {
+		return string(body)
+	}
+	var scrubbed strings.Builder
+	last := 0
+	for _, span := range spans {
+		scrubbed.Write(body[last:span.start])
+		scrubbed.WriteString(`"` + Redacted + `"`)
+		last = span.end
+	}
+	scrubbed.Write(body[last:])
+	return scrubbed.String()
+}
+
+// secrets lists the values redacted from an interaction: its API key and the scrubbed
+// JSON fields of both bodies, longest first so that no secret is left half replaced
+func (r *Recorder) secrets(header http.Header, bodies ...[]byte) []string {
+	var secrets []string
+	if key := header.Get(gladiaHeaderKey); key != "" {
+		secrets = append(secrets, key)
+	}
+	for _, body := range bodies {
+		spans, err := r.scrubbedSpans(body)
+		if err != nil {
+			continue
+		}
+		for _, span := range spans {
+			secrets = append(secrets, span.value)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	return secrets
+}
+
+// span is a non-empty JSON string value to redact, located by its byte offsets
+type span struct {
+	start, end int
+	value      string
+}
+
+// scrubbedSpans finds the string values of scrubbed fields in a JSON document, at any depth
+func (r *Recorder) scrubbedSpans(body []byte) ([]span, error) {
+	if !json.Valid(body) {
+		return nil, errors.New("invalid JSON")
+	}
+
+	// Each open object or array has a frame; objects alternate between keys and values
+	type frame struct {
+		object bool
+		key    bool
+	}
+	var (
+		stack []frame
+		spans []span
+	)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return spans, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		isKey := false
+		if top := len(stack) - 1; top >= 0 && stack[top].object {
+			isKey = stack[top].key
+			stack[top].key = !stack[top].key
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, frame{object: true, key: true})
+			case '[':
+				stack = append(stack, frame{})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case string:
+			if !isKey || !r.scrubbed(t) {
+				continue
+			}
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+			stack[len(stack)-1].key = true
+			var value string
+			if json.Unmarshal(raw, &value) == nil && value != "" {
+				end := int(dec.InputOffset())
+				spans = append(spans, span{start: end - len(raw), end: end, value: value})
+			}
+		}
+	}
+}
+
+func (r *Recorder) scrubbed(key string) bool {
+	for _, field := range r.scrub {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
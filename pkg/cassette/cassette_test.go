@@ -0,0 +1,177 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const apiKey = "secret-api-key"
+
+// api answers like the Gladia API with bodies that exercise scrubbing
+func api(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + strings.TrimSuffix(r.URL.Path, "/") {
+		case "POST /v2/pre-recorded":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"id":"job-1","result_url":"https://api.gladia.io/v2/pre-recorded/job-1"}`)
+		case "GET /v2/pre-recorded/job-1":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"id":"job-1","status":"done","file":{"source":"https://storage/call.wav"},`+
+				`"custom_metadata":{"order":12345678901234567890,"z":1,"a":2}}`)
+		default:
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "no route for key "+r.Header.Get(gladiaHeaderKey))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func record(t *testing.T, path string, requests func(client *gladia.Client)) {
+	t.Helper()
+	server := api(t)
+	recorder, err := New(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests(gladia.NewClient(apiKey, gladia.WithBaseURL(server.URL+"/"), gladia.WithHTTPClient(recorder)))
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func replay(t *testing.T, path string, opts ...Option) *gladia.Client {
+	t.Helper()
+	recorder, err := New(path, ModeReplay, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gladia.NewClient(apiKey, gladia.WithBaseURL("http://replay.invalid/"), gladia.WithHTTPClient(recorder))
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+	record(t, path, func(client *gladia.Client) {
+		if _, err := client.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{AudioURL: "https://storage/call.wav"}); err != nil {
+			t.Fatalf("TranscribeWithRequest: %v", err)
+		}
+		if _, err := client.GetTranscriptionResult(ctx, "job-1"); err != nil {
+			t.Fatalf("GetTranscriptionResult: %v", err)
+		}
+		client.DeleteTranscription(ctx, "missing")
+	})
+
+	recorder, err := New(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interactions := recorder.Interactions()
+	if len(interactions) != 3 {
+		t.Fatalf("recorded %d interactions, want 3", len(interactions))
+	}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "API key header", got: interactions[0].Request.Header.Get(gladiaHeaderKey), want: Redacted},
+		{name: "request field", got: interactions[0].Request.Body, want: `"audio_url":"REDACTED"`},
+		{name: "response field", got: interactions[0].Response.Body, want: `{"id":"job-1","result_url":"REDACTED"}`},
+		{name: "nested field", got: interactions[1].Response.Body, want: `"file":{"source":"REDACTED"}`},
+		{name: "numbers and key order", got: interactions[1].Response.Body, want: `{"order":12345678901234567890,"z":1,"a":2}`},
+		{name: "text body", got: interactions[2].Response.Body, want: "no route for key REDACTED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(tt.got, tt.want) {
+				t.Errorf("recorded %q, want it to contain %q", tt.got, tt.want)
+			}
+			if strings.Contains(tt.got, apiKey) || strings.Contains(tt.got, "https://") {
+				t.Errorf("recorded %q leaks a secret", tt.got)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+	record(t, path, func(client *gladia.Client) {
+		client.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{AudioURL: "https://storage/call.wav"})
+		client.GetTranscriptionResult(ctx, "job-1")
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		client := replay(t, path)
+		job, err := client.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{})
+		if err != nil || job.ID != "job-1" {
+			t.Fatalf("TranscribeWithRequest = %+v, %v", job, err)
+		}
+		result, err := client.GetTranscriptionResult(ctx, "job-1")
+		if err != nil || result.Status != gladia.StatusDone {
+			t.Fatalf("GetTranscriptionResult = %+v, %v", result, err)
+		}
+		if _, err := client.GetTranscriptionResult(ctx, "job-1"); err == nil {
+			t.Error("replayed past the end of the cassette")
+		}
+	})
+
+	t.Run("in order rejects a different request", func(t *testing.T) {
+		client := replay(t, path)
+		if _, err := client.GetTranscriptionResult(ctx, "job-1"); err == nil || !strings.Contains(err.Error(), "does not match") {
+			t.Fatalf("out of order request = %v, want a mismatch error", err)
+		}
+		if _, err := client.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{}); err != nil {
+			t.Errorf("matching request after a mismatch = %v, want the first interaction", err)
+		}
+	})
+
+	t.Run("by method and path", func(t *testing.T) {
+		client := replay(t, path, WithMatcher(MatchMethodPath))
+		if _, err := client.GetTranscriptionResult(ctx, "job-1"); err != nil {
+			t.Fatalf("GetTranscriptionResult: %v", err)
+		}
+		if _, err := client.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{}); err != nil {
+			t.Fatalf("TranscribeWithRequest: %v", err)
+		}
+		if _, err := client.GetTranscriptionResult(ctx, "job-1"); err == nil {
+			t.Error("replayed an interaction twice")
+		}
+	})
+}
+
+func TestScrubBody(t *testing.T) {
+	r := &Recorder{scrub: []string{"audio_url"}}
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "formatting kept", contentType: "application/json", body: "{\n  \"audio_url\": \"x\",\n  \"n\": 1.50\n}", want: "{\n  \"audio_url\": \"REDACTED\",\n  \"n\": 1.50\n}"},
+		{name: "in arrays", contentType: "application/json", body: `[{"audio_url":"x"},{"audio_url":"y"}]`, want: `[{"audio_url":"REDACTED"},{"audio_url":"REDACTED"}]`},
+		{name: "non-string values kept", contentType: "application/json", body: `{"audio_url":null,"b":{"audio_url":""}}`, want: `{"audio_url":null,"b":{"audio_url":""}}`},
+		{name: "field name as a value", contentType: "application/json", body: `{"a":"audio_url","b":"c"}`, want: `{"a":"audio_url","b":"c"}`},
+		{name: "invalid JSON kept", contentType: "application/json", body: `{"audio_url":`, want: `{"audio_url":`},
+		{name: "binary dropped", contentType: "audio/wav", body: "RIFF", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.scrubBody(tt.contentType, []byte(tt.body), nil); got != tt.want {
+				t.Errorf("scrubBody = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package chunk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/audio"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const defaultOverlap = 5 * time.Second
+
+// Transcriber is the part of gladia.Client used to transcribe chunks
+type Transcriber interface {
+	UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error)
+	TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error)
+	WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error)
+}
+
+// Options configures a chunked transcription
+type Options struct {
+	// SplitOptions sets where chunks are cut. Unlike audio.Split, Overlap defaults to
+	// 5 seconds so words at a cut are heard whole by one chunk; set it negative for none.
+	audio.SplitOptions
+	// Concurrency is the number of chunks transcribed at once. Defaults to 4.
+	Concurrency int
+	// Request is the template of every chunk request; its audio URL is replaced
+	Request gladia.TranscriptionRequest
+	// WaitOptions are passed to WaitForTranscription for every chunk. With
+	// gladia.WithCancelRemoteOnContextDone the jobs of other chunks are deleted when one fails.
+	WaitOptions []gladia.WaitOption
+}
+
+// Result is a transcription stitched together from chunks
+type Result struct {
+	Transcription gladia.TranscriptionData
+	Metadata      gladia.TranscriptionMetadata
+	Chunks        []Part
+}
+
+// Part is the transcription of one chunk. Times are in seconds from the start of the
+// whole recording: the chunk audio begins at Start and the chunk owns [Cut, NextCut).
+type Part struct {
+	Index           int
+	TranscriptionID string
+	Start           float64
+	Cut             float64
+	NextCut         float64
+	Result          *gladia.CompletedTranscriptionResult
+}
+
+// TranscribeFile transcribes a WAV file in chunks
+func TranscribeFile(ctx context.Context, client Transcriber, path string, opts Options) (*Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	header, err := audio.ReadWAVHeader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return transcribe(ctx, client, file, header, name, opts)
+}
+
+// Transcribe splits PCM audio described by header into chunks, transcribes them in
+// parallel and merges the results
+func Transcribe(ctx context.Context, client Transcriber, r io.ReaderAt, header *audio.WAVHeader, opts Options) (*Result, error) {
+	return transcribe(ctx, client, r, header, "audio", opts)
+}
+
+func transcribe(ctx context.Context, client Transcriber, r io.ReaderAt, header *audio.WAVHeader, name string, opts Options) (*Result, error) {
+	switch {
+	case opts.Overlap == 0:
+		opts.Overlap = defaultOverlap
+	case opts.Overlap < 0:
+		opts.Overlap = 0
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	chunks, err := audio.Split(r, header, opts.SplitOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first failure cancels the other chunks; their cancellation errors are not reported
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+		cancel()
+	}
+
+	parts := make([]Part, len(chunks))
+	semaphore := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	for i, c := range chunks {
+		parts[i] = Part{
+			Index:   c.Index,
+			Start:   header.Seconds(c.Start),
+			Cut:     header.Seconds(c.Cut),
+			NextCut: header.Seconds(c.NextCut),
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				fail(ctx.Err())
+				return
+			}
+
+			filename := fmt.Sprintf("%s-%03d.wav", name, c.Index)
+			id, result, err := transcribeChunk(ctx, client, audio.Section(r, header, c.Start, c.End), filename, opts)
+			parts[i].TranscriptionID = id
+			parts[i].Result = result
+			if err != nil {
+				fail(fmt.Errorf("chunk %d: %w", c.Index, err))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return Merge(parts), nil
+}
+
+func transcribeChunk(ctx context.Context, client Transcriber, r io.Reader, filename string, opts Options) (string, *gladia.CompletedTranscriptionResult, error) {
+	upload, err := client.UploadReader(ctx, filename, r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	req := opts.Request
+	req.AudioURL = upload.AudioURL
+	job, err := client.TranscribeWithRequest(ctx, &req)
+	if err != nil {
+		return "", nil, err
+	}
+
+	result, err := client.WaitForTranscription(ctx, job.ID, opts.WaitOptions...)
+	return job.ID, result, err
+}
@@ -0,0 +1,168 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/audio"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const sampleRate = 100
+
+// recording returns mono 16-bit PCM whose samples hold the second they belong to, so a
+// fake transcriber can tell which part of the recording a chunk covers
+func recording(seconds int) (*bytes.Reader, *audio.WAVHeader) {
+	data := make([]byte, seconds*sampleRate*2)
+	for i := 0; i < seconds*sampleRate; i++ {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(100*(i/sampleRate)+1))
+	}
+	header := audio.PCMHeader(audio.PCMFormat{SampleRate: sampleRate, Channels: 1, BitsPerSample: 16}, int64(len(data)))
+	return bytes.NewReader(data), header
+}
+
+// fakeTranscriber transcribes every second of a chunk as the word "w<second>", timed
+// relative to the start of the chunk
+type fakeTranscriber struct {
+	mu     sync.Mutex
+	audio  map[string][]byte
+	failAt int
+	err    error
+}
+
+func (f *fakeTranscriber) UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.audio == nil {
+		f.audio = make(map[string][]byte)
+	}
+	url := fmt.Sprintf("mem://%d", len(f.audio))
+	f.audio[url] = data
+	return &gladia.UploadResponse{AudioURL: url}, nil
+}
+
+func (f *fakeTranscriber) TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error) {
+	return &gladia.TranscriptionResponse{ID: req.AudioURL}, nil
+}
+
+func (f *fakeTranscriber) WaitForTranscription(ctx context.Context, id string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error) {
+	f.mu.Lock()
+	data := f.audio[id]
+	f.mu.Unlock()
+
+	header, err := audio.ReadWAVHeader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	samples := data[header.DataOffset:]
+	second := func(frame int) int {
+		return int(binary.LittleEndian.Uint16(samples[2*frame:])-1) / 100
+	}
+	frames := len(samples) / 2
+
+	if f.err != nil {
+		if second(0) <= f.failAt && f.failAt <= second(frames-1) {
+			time.Sleep(10 * time.Millisecond)
+			return nil, f.err
+		}
+		// Other chunks only finish when they are canceled
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	var words []gladia.Word
+	var text []string
+	for from := 0; from < frames; {
+		to := from
+		for to < frames && second(to) == second(from) {
+			to++
+		}
+		word := fmt.Sprintf("w%d", second(from))
+		words = append(words, gladia.Word{Word: " " + word, Start: float64(from) / sampleRate, End: float64(to) / sampleRate})
+		text = append(text, word)
+		from = to
+	}
+
+	result := &gladia.CompletedTranscriptionResult{ID: id, Status: gladia.StatusDone}
+	result.Result.Metadata.BillingTime = float64(frames) / sampleRate
+	result.Result.Transcription.Utterances = []gladia.Utterance{{
+		Start: words[0].Start,
+		End:   words[len(words)-1].End,
+		Text:  strings.Join(text, " "),
+		Words: words,
+	}}
+	return result, nil
+}
+
+func TestTranscribe(t *testing.T) {
+	const seconds = 60
+	r, header := recording(seconds)
+	opts := Options{
+		SplitOptions: audio.SplitOptions{TargetLength: 20 * time.Second, SearchWindow: 3 * time.Second, Overlap: 2 * time.Second},
+		Concurrency:  2,
+	}
+
+	result, err := Transcribe(context.Background(), &fakeTranscriber{}, r, header, opts)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if len(result.Chunks) < 3 {
+		t.Fatalf("got %d chunks, want at least 3", len(result.Chunks))
+	}
+
+	var want []string
+	for s := range seconds {
+		want = append(want, fmt.Sprintf("w%d", s))
+	}
+	if got := result.Transcription.FullTranscript; got != strings.Join(want, " ") {
+		t.Errorf("transcript = %q, want every second once and in order", got)
+	}
+
+	for _, u := range result.Transcription.Utterances {
+		for _, w := range u.Words {
+			var s int
+			fmt.Sscanf(strings.TrimSpace(w.Word), "w%d", &s)
+			if w.Start != float64(s) || w.End != float64(s+1) {
+				t.Errorf("word %s at [%.2f, %.2f), want it rebased to [%d, %d)", w.Word, w.Start, w.End, s, s+1)
+			}
+		}
+	}
+
+	for i, part := range result.Chunks {
+		if i > 0 && part.Cut != result.Chunks[i-1].NextCut {
+			t.Errorf("chunk %d starts owning at %.2f, previous chunk stops at %.2f", i, part.Cut, result.Chunks[i-1].NextCut)
+		}
+		if part.Start > part.Cut || part.Cut-part.Start > 2 {
+			t.Errorf("chunk %d audio starts at %.2f for a cut at %.2f, want 2s of overlap", i, part.Start, part.Cut)
+		}
+	}
+	if result.Metadata.BillingTime < seconds {
+		t.Errorf("billing time = %.2f, want the sum of the chunks", result.Metadata.BillingTime)
+	}
+}
+
+func TestTranscribeReportsTheFailingChunk(t *testing.T) {
+	r, header := recording(60)
+	failure := errors.New("chunk rejected")
+	opts := Options{
+		SplitOptions: audio.SplitOptions{TargetLength: 20 * time.Second, SearchWindow: 3 * time.Second},
+		Concurrency:  4,
+	}
+
+	_, err := Transcribe(context.Background(), &fakeTranscriber{failAt: 45, err: failure}, r, header, opts)
+	if !errors.Is(err, failure) {
+		t.Errorf("got %v, want the failing chunk's error rather than a sibling's cancellation", err)
+	}
+}
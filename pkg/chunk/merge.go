@@ -0,0 +1,170 @@
+package chunk
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Merge stitches chunk transcriptions into one. Timestamps are shifted by each chunk's
+// start, words and utterances outside the range a chunk owns are dropped so overlaps are
+// not duplicated, and speakers are matched across chunks by how much they talk at the same
+// time in the overlapping audio. Sentences and subtitles are not merged.
+func Merge(parts []Part) *Result {
+	sorted := append([]Part(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	result := &Result{Chunks: sorted}
+	languages := make(map[string]bool)
+	var previous []gladia.Utterance
+	nextSpeaker := 0
+
+	for i, part := range sorted {
+		if part.Result == nil {
+			continue
+		}
+		data := part.Result.Result
+
+		shifted := shift(data.Transcription.Utterances, part.Start)
+		mapping := matchSpeakers(previous, shifted)
+		for _, u := range shifted {
+			if _, ok := mapping[u.Speaker]; !ok {
+				mapping[u.Speaker] = nextSpeaker
+				nextSpeaker++
+			}
+		}
+		for j := range shifted {
+			shifted[j].Speaker = mapping[shifted[j].Speaker]
+			nextSpeaker = max(nextSpeaker, shifted[j].Speaker+1)
+		}
+		previous = shifted
+
+		end := part.NextCut
+		if i == len(sorted)-1 {
+			end = math.Inf(1)
+		}
+		for _, u := range shifted {
+			if owned, ok := own(u, part.Cut, end); ok {
+				result.Transcription.Utterances = append(result.Transcription.Utterances, owned)
+			}
+		}
+
+		for _, language := range data.Transcription.Languages {
+			if !languages[language] {
+				languages[language] = true
+				result.Transcription.Languages = append(result.Transcription.Languages, language)
+			}
+		}
+
+		metadata := data.Metadata
+		result.Metadata.BillingTime += metadata.BillingTime
+		result.Metadata.TranscriptionTime += metadata.TranscriptionTime
+		result.Metadata.NumberOfDistinctChannels = max(result.Metadata.NumberOfDistinctChannels, metadata.NumberOfDistinctChannels)
+		result.Metadata.AudioDuration = max(result.Metadata.AudioDuration, part.Start+metadata.AudioDuration)
+	}
+
+	texts := make([]string, 0, len(result.Transcription.Utterances))
+	for _, u := range result.Transcription.Utterances {
+		texts = append(texts, strings.TrimSpace(u.Text))
+	}
+	result.Transcription.FullTranscript = strings.Join(texts, " ")
+
+	return result
+}
+
+// shift copies utterances moving every timestamp by offset seconds
+func shift(utterances []gladia.Utterance, offset float64) []gladia.Utterance {
+	shifted := make([]gladia.Utterance, len(utterances))
+	for i, u := range utterances {
+		u.Start += offset
+		u.End += offset
+		words := make([]gladia.Word, len(u.Words))
+		for j, w := range u.Words {
+			w.Start += offset
+			w.End += offset
+			words[j] = w
+		}
+		u.Words = words
+		shifted[i] = u
+	}
+	return shifted
+}
+
+// own keeps the part of an utterance whose words fall in [from, to). Utterances without
+// words are kept whole if their midpoint falls in the range.
+func own(u gladia.Utterance, from, to float64) (gladia.Utterance, bool) {
+	inRange := func(start, end float64) bool {
+		mid := (start + end) / 2
+		return mid >= from && mid < to
+	}
+
+	if len(u.Words) == 0 {
+		return u, inRange(u.Start, u.End)
+	}
+
+	var kept []gladia.Word
+	for _, w := range u.Words {
+		if inRange(w.Start, w.End) {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return u, false
+	}
+	if len(kept) == len(u.Words) {
+		return u, true
+	}
+
+	var text strings.Builder
+	for _, w := range kept {
+		text.WriteString(w.Word)
+	}
+	u.Words = kept
+	u.Start = kept[0].Start
+	u.End = kept[len(kept)-1].End
+	u.Text = strings.TrimSpace(text.String())
+	return u, true
+}
+
+// matchSpeakers maps the speakers of current to the speakers of previous that talk over
+// the same audio the longest, pairing each speaker at most once
+func matchSpeakers(previous, current []gladia.Utterance) map[int]int {
+	type pair struct {
+		local, global int
+	}
+	overlap := make(map[pair]float64)
+	for _, c := range current {
+		for _, p := range previous {
+			if d := math.Min(c.End, p.End) - math.Max(c.Start, p.Start); d > 0 {
+				overlap[pair{c.Speaker, p.Speaker}] += d
+			}
+		}
+	}
+
+	pairs := make([]pair, 0, len(overlap))
+	for p := range overlap {
+		pairs = append(pairs, p)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if overlap[pairs[i]] != overlap[pairs[j]] {
+			return overlap[pairs[i]] > overlap[pairs[j]]
+		}
+		if pairs[i].local != pairs[j].local {
+			return pairs[i].local < pairs[j].local
+		}
+		return pairs[i].global < pairs[j].global
+	})
+
+	mapping := make(map[int]int)
+	used := make(map[int]bool)
+	for _, p := range pairs {
+		if _, mapped := mapping[p.local]; mapped || used[p.global] {
+			continue
+		}
+		mapping[p.local] = p.global
+		used[p.global] = true
+	}
+	return mapping
+}
@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+)
 
 type Error struct {
 	Code    int
@@ -16,4 +19,13 @@ func New(code int, message string) error {
 		Code:    code,
 		Message: message,
 	}
-}
\ No newline at end of file
+}
+
+// StatusCode returns the code of the first Error found in err's chain
+func StatusCode(err error) (int, bool) {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e.Code, true
+	}
+	return 0, false
+}
@@ -0,0 +1,276 @@
+package eval
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// frame is the resolution, in seconds, used to compare speaker segments
+const frame = 0.01
+
+// Segment is a span of speech attributed to a speaker
+type Segment struct {
+	File    string
+	Speaker string
+	Start   float64
+	End     float64
+}
+
+// DERResult is the outcome of a diarization error rate computation. Durations are in seconds.
+type DERResult struct {
+	Total      float64
+	Missed     float64
+	FalseAlarm float64
+	Confusion  float64
+	// Rate is (missed + false alarm + confusion) / total reference speech
+	Rate float64
+	// Mapping pairs each hypothesis speaker with the reference speaker it was matched to.
+	// It is only set when all segments share one timeline; see Files.
+	Mapping map[string]string
+	// Files holds the result of each file when segments come from several files
+	Files map[string]*DERResult
+}
+
+// ParseRTTM reads speaker segments from RTTM. Only SPEAKER lines are used.
+func ParseRTTM(r io.Reader) ([]Segment, error) {
+	var segments []Segment
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || fields[0] != "SPEAKER" {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("invalid RTTM line %d: expected at least 8 fields", line)
+		}
+
+		start, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RTTM line %d: bad onset: %w", line, err)
+		}
+		duration, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RTTM line %d: bad duration: %w", line, err)
+		}
+
+		segments = append(segments, Segment{
+			File:    fields[1],
+			Speaker: fields[7],
+			Start:   start,
+			End:     start + duration,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read RTTM: %w", err)
+	}
+	return segments, nil
+}
+
+// LoadRTTM reads speaker segments from an RTTM file
+func LoadRTTM(path string) ([]Segment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open RTTM: %w", err)
+	}
+	defer file.Close()
+
+	return ParseRTTM(file)
+}
+
+// Segments returns the speaker segments of a transcription's utterances
+func Segments(data *gladia.TranscriptionData) []Segment {
+	segments := make([]Segment, 0, len(data.Utterances))
+	for _, u := range data.Utterances {
+		segments = append(segments, Segment{
+			Speaker: fmt.Sprintf("speaker_%d", u.Speaker),
+			Start:   u.Start,
+			End:     u.End,
+		})
+	}
+	return segments
+}
+
+// DER computes the diarization error rate of hypothesis against reference segments.
+// Hypothesis speakers are mapped to reference speakers greedily by overlapping time.
+// When each side covers a single file, named or not, both are scored on one timeline
+// whatever the names, so segments returned by Segments match a one-file RTTM directly.
+// Otherwise each file is scored on its own timeline with its own speaker mapping and the
+// durations are summed; tag transcript segments with WithFile to score them against one
+// file of a larger RTTM set.
+func DER(reference, hypothesis []Segment) *DERResult {
+	if len(namedFiles(reference)) <= 1 && len(namedFiles(hypothesis)) <= 1 {
+		return derTimeline(reference, hypothesis)
+	}
+
+	files := segmentFiles(reference, hypothesis)
+
+	result := &DERResult{Files: make(map[string]*DERResult, len(files))}
+	for file := range files {
+		fileResult := derTimeline(fileSegments(reference, file), fileSegments(hypothesis, file))
+		result.Files[file] = fileResult
+		result.Total += fileResult.Total
+		result.Missed += fileResult.Missed
+		result.FalseAlarm += fileResult.FalseAlarm
+		result.Confusion += fileResult.Confusion
+	}
+
+	if result.Total > 0 {
+		result.Rate = (result.Missed + result.FalseAlarm + result.Confusion) / result.Total
+	}
+	return result
+}
+
+// WithFile returns a copy of segments attributed to file
+func WithFile(segments []Segment, file string) []Segment {
+	tagged := make([]Segment, len(segments))
+	for i, s := range segments {
+		s.File = file
+		tagged[i] = s
+	}
+	return tagged
+}
+
+// namedFiles returns the distinct file names of segments, ignoring segments without one
+func namedFiles(segments []Segment) map[string]bool {
+	files := make(map[string]bool)
+	for _, s := range segments {
+		if s.File != "" {
+			files[s.File] = true
+		}
+	}
+	return files
+}
+
+// segmentFiles returns the files of both sides; segments without a file form their own group
+func segmentFiles(reference, hypothesis []Segment) map[string]bool {
+	files := make(map[string]bool)
+	for _, segments := range [][]Segment{reference, hypothesis} {
+		for _, s := range segments {
+			files[s.File] = true
+		}
+	}
+	return files
+}
+
+func fileSegments(segments []Segment, file string) []Segment {
+	var selected []Segment
+	for _, s := range segments {
+		if s.File == file {
+			selected = append(selected, s)
+		}
+	}
+	return selected
+}
+
+// derTimeline scores segments that share one timeline
+func derTimeline(reference, hypothesis []Segment) *DERResult {
+	end := 0.0
+	for _, s := range append(append([]Segment(nil), reference...), hypothesis...) {
+		end = max(end, s.End)
+	}
+	frames := int(math.Ceil(end / frame))
+
+	refFrames := speakerFrames(reference, frames)
+	hypFrames := speakerFrames(hypothesis, frames)
+
+	// overlap[hyp][ref] counts frames where both speakers talk
+	overlap := make(map[string]map[string]int)
+	for i := 0; i < frames; i++ {
+		for _, h := range hypFrames[i] {
+			for _, r := range refFrames[i] {
+				if overlap[h] == nil {
+					overlap[h] = make(map[string]int)
+				}
+				overlap[h][r]++
+			}
+		}
+	}
+	mapping := greedyMapping(overlap)
+
+	result := &DERResult{Mapping: mapping}
+	for i := 0; i < frames; i++ {
+		refs, hyps := refFrames[i], hypFrames[i]
+		correct := 0
+		for _, h := range hyps {
+			if r, ok := mapping[h]; ok && contains(refs, r) {
+				correct++
+			}
+		}
+
+		result.Total += float64(len(refs)) * frame
+		result.Missed += float64(max(len(refs)-len(hyps), 0)) * frame
+		result.FalseAlarm += float64(max(len(hyps)-len(refs), 0)) * frame
+		result.Confusion += float64(min(len(refs), len(hyps))-correct) * frame
+	}
+
+	if result.Total > 0 {
+		result.Rate = (result.Missed + result.FalseAlarm + result.Confusion) / result.Total
+	}
+	return result
+}
+
+// speakerFrames lists the distinct speakers talking in each frame
+func speakerFrames(segments []Segment, frames int) [][]string {
+	speakers := make([][]string, frames)
+	for _, s := range segments {
+		first := int(math.Round(s.Start / frame))
+		last := min(int(math.Round(s.End/frame)), frames)
+		for i := max(first, 0); i < last; i++ {
+			if !contains(speakers[i], s.Speaker) {
+				speakers[i] = append(speakers[i], s.Speaker)
+			}
+		}
+	}
+	return speakers
+}
+
+// greedyMapping pairs hypothesis and reference speakers by decreasing overlap
+func greedyMapping(overlap map[string]map[string]int) map[string]string {
+	type pair struct {
+		hyp, ref string
+		frames   int
+	}
+	var pairs []pair
+	for h, refs := range overlap {
+		for r, n := range refs {
+			pairs = append(pairs, pair{h, r, n})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].frames != pairs[j].frames {
+			return pairs[i].frames > pairs[j].frames
+		}
+		if pairs[i].hyp != pairs[j].hyp {
+			return pairs[i].hyp < pairs[j].hyp
+		}
+		return pairs[i].ref < pairs[j].ref
+	})
+
+	mapping := make(map[string]string)
+	used := make(map[string]bool)
+	for _, p := range pairs {
+		if _, mapped := mapping[p.hyp]; mapped || used[p.ref] {
+			continue
+		}
+		mapping[p.hyp] = p.ref
+		used[p.ref] = true
+	}
+	return mapping
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
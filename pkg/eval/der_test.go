@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDERScoresFilesSeparately(t *testing.T) {
+	// The same hypothesis label is a different person in each file, which a single
+	// timeline would count as confusion
+	reference := []Segment{
+		{File: "a", Speaker: "alice", Start: 0, End: 10},
+		{File: "b", Speaker: "bob", Start: 0, End: 10},
+	}
+	hypothesis := []Segment{
+		{File: "a", Speaker: "speaker_0", Start: 0, End: 10},
+		{File: "b", Speaker: "speaker_0", Start: 0, End: 8},
+	}
+
+	result := DER(reference, hypothesis)
+	if math.Abs(result.Total-20) > 1e-6 || math.Abs(result.Missed-2) > 1e-6 || result.Confusion > 1e-6 {
+		t.Errorf("got total %.2f, missed %.2f, confusion %.2f; want 20, 2, 0",
+			result.Total, result.Missed, result.Confusion)
+	}
+	if len(result.Files) != 2 || result.Files["b"].Mapping["speaker_0"] != "bob" {
+		t.Errorf("unexpected per-file results %+v", result.Files)
+	}
+}
+
+func TestDERSingleFileAcceptsUnnamedHypothesis(t *testing.T) {
+	reference := []Segment{{File: "a", Speaker: "alice", Start: 0, End: 5}}
+	hypothesis := []Segment{{Speaker: "speaker_0", Start: 0, End: 5}}
+
+	result := DER(reference, hypothesis)
+	if result.Rate > 1e-6 || result.Files != nil {
+		t.Errorf("got rate %.3f and files %v, want a perfect single-timeline score", result.Rate, result.Files)
+	}
+}
+
+func TestDERSingleFilesWithDifferentNames(t *testing.T) {
+	reference := []Segment{{File: "meeting", Speaker: "alice", Start: 0, End: 5}}
+	hypothesis := []Segment{{File: "meeting.wav", Speaker: "speaker_0", Start: 0, End: 5}}
+
+	if result := DER(reference, hypothesis); result.Rate > 1e-6 {
+		t.Errorf("got rate %.3f, want the two single files compared on one timeline", result.Rate)
+	}
+}
+
+func TestDERWithFileSelectsFromSet(t *testing.T) {
+	reference := []Segment{
+		{File: "a", Speaker: "alice", Start: 0, End: 5},
+		{File: "b", Speaker: "bob", Start: 0, End: 5},
+	}
+	hypothesis := WithFile([]Segment{{Speaker: "speaker_0", Start: 0, End: 5}}, "b")
+
+	result := DER(reference, hypothesis)
+	if got := result.Files["b"]; got == nil || got.Rate > 1e-6 || got.Mapping["speaker_0"] != "bob" {
+		t.Errorf("file b scored %+v, want a perfect match with bob", got)
+	}
+	if _, ok := result.Files[""]; ok {
+		t.Error("tagged segments were scored as a separate unnamed group")
+	}
+}
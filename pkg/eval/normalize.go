@@ -0,0 +1,153 @@
+package eval
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Normalization controls how texts are cleaned up before being compared
+type Normalization struct {
+	// KeepCase compares words case-sensitively
+	KeepCase bool
+	// KeepPunctuation keeps punctuation attached to words
+	KeepPunctuation bool
+	// SpellNumbers rewrites numerals as English words, so "42" matches "forty-two" and
+	// "3.5" matches "three point five"
+	SpellNumbers bool
+}
+
+// Words splits text into normalized words. Hyphenated words are split unless punctuation
+// is kept, and numerals are spelled out before punctuation is removed, so "3.5" becomes
+// "three point five" rather than "thirty five".
+func (n Normalization) Words(text string) []string {
+	if !n.KeepCase {
+		text = strings.ToLower(text)
+	}
+
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if n.SpellNumbers {
+			if spelled, ok := spellNumeral(strings.TrimFunc(field, isPunctuation)); ok {
+				words = append(words, strings.Fields(spelled)...)
+				continue
+			}
+		}
+
+		parts := []string{field}
+		if !n.KeepPunctuation {
+			parts = strings.FieldsFunc(field, func(r rune) bool {
+				return unicode.Is(unicode.Dash, r)
+			})
+		}
+		for _, part := range parts {
+			if !n.KeepPunctuation {
+				part = strings.Map(func(r rune) rune {
+					// Keep apostrophes inside words such as "don't"
+					if isPunctuation(r) && r != '\'' {
+						return -1
+					}
+					return r
+				}, part)
+				part = strings.Trim(part, "'")
+			}
+			if part == "" {
+				continue
+			}
+			if n.SpellNumbers {
+				if spelled, ok := spellNumeral(part); ok {
+					words = append(words, strings.Fields(spelled)...)
+					continue
+				}
+			}
+			words = append(words, part)
+		}
+	}
+	return words
+}
+
+func isPunctuation(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// groupedDigits matches whole numbers written with thousands separators, such as 1,000
+var groupedDigits = regexp.MustCompile(`^[0-9]{1,3}(,[0-9]{3})+$`)
+
+// spellNumeral spells a numeral such as "42", "1,000" or "3.5" in English words, reading
+// decimals digit by digit. It reports false for anything else.
+func spellNumeral(s string) (string, bool) {
+	whole, fraction, decimal := strings.Cut(s, ".")
+	if groupedDigits.MatchString(whole) {
+		whole = strings.ReplaceAll(whole, ",", "")
+	}
+	if !isDigits(whole) || decimal && !isDigits(fraction) {
+		return "", false
+	}
+	number, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return "", false
+	}
+
+	words := spellNumber(number)
+	if decimal {
+		words += " point"
+		for _, digit := range fraction {
+			words += " " + smallNumbers[digit-'0']
+		}
+	}
+	return words, true
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	smallNumbers = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	tens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	scales = []struct {
+		value int64
+		name  string
+	}{
+		{1_000_000_000_000, "trillion"},
+		{1_000_000_000, "billion"},
+		{1_000_000, "million"},
+		{1_000, "thousand"},
+		{100, "hundred"},
+	}
+)
+
+// spellNumber writes a non-negative number in English words without "and" or hyphens
+func spellNumber(n int64) string {
+	if n < 20 {
+		return smallNumbers[n]
+	}
+	if n < 100 {
+		if n%10 == 0 {
+			return tens[n/10]
+		}
+		return tens[n/10] + " " + smallNumbers[n%10]
+	}
+	for _, scale := range scales {
+		if n >= scale.value {
+			words := spellNumber(n/scale.value) + " " + scale.name
+			if rest := n % scale.value; rest > 0 {
+				words += " " + spellNumber(rest)
+			}
+			return words
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
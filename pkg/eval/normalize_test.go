@@ -0,0 +1,49 @@
+package eval
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		n    Normalization
+		text string
+		want []string
+	}{
+		{name: "case and punctuation", text: "Hello, World!", want: []string{"hello", "world"}},
+		{name: "apostrophes", text: "Don't 'quote' me", want: []string{"don't", "quote", "me"}},
+		{name: "hyphens", text: "a well-known fact", want: []string{"a", "well", "known", "fact"}},
+		{name: "kept punctuation", n: Normalization{KeepPunctuation: true}, text: "well-known, fact", want: []string{"well-known,", "fact"}},
+		{name: "kept case", n: Normalization{KeepCase: true}, text: "Gladia API", want: []string{"Gladia", "API"}},
+		{name: "whole number", n: Normalization{SpellNumbers: true}, text: "42 apples.", want: []string{"forty", "two", "apples"}},
+		{name: "hyphenated number", n: Normalization{SpellNumbers: true}, text: "forty-two", want: []string{"forty", "two"}},
+		{name: "decimal", n: Normalization{SpellNumbers: true}, text: "3.5%", want: []string{"three", "point", "five"}},
+		{name: "thousands separator", n: Normalization{SpellNumbers: true}, text: "1,200 people", want: []string{"one", "thousand", "two", "hundred", "people"}},
+		{name: "number ending a sentence", n: Normalization{SpellNumbers: true}, text: "It costs 12.", want: []string{"it", "costs", "twelve"}},
+		{name: "numbers left alone", text: "3.5", want: []string{"35"}},
+		{name: "not a numeral", n: Normalization{SpellNumbers: true}, text: "v1.2 mp3", want: []string{"v12", "mp3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.Words(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWERSpellsNumbers(t *testing.T) {
+	tests := []struct{ reference, hypothesis string }{
+		{"forty-two", "42"},
+		{"three point five", "3.5"},
+		{"one thousand two hundred", "1,200"},
+	}
+	for _, tt := range tests {
+		if result := WER(tt.reference, tt.hypothesis, Normalization{SpellNumbers: true}); result.Rate != 0 {
+			t.Errorf("WER(%q, %q) = %.2f, want 0", tt.reference, tt.hypothesis, result.Rate)
+		}
+	}
+}
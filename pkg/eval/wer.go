@@ -0,0 +1,293 @@
+package eval
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// OpKind is the kind of an alignment operation
+type OpKind int
+
+// Alignment operation kinds
+const (
+	OpMatch OpKind = iota
+	OpSubstitution
+	OpInsertion
+	OpDeletion
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpSubstitution:
+		return "substitution"
+	case OpInsertion:
+		return "insertion"
+	case OpDeletion:
+		return "deletion"
+	default:
+		return "match"
+	}
+}
+
+// Op is a step of the alignment between a reference and a hypothesis.
+// Indexes are -1 when the op has no token on that side.
+type Op struct {
+	Kind       OpKind
+	Reference  string
+	Hypothesis string
+	RefIndex   int
+	HypIndex   int
+}
+
+// Result is the outcome of comparing a hypothesis with a reference
+type Result struct {
+	Hits            int
+	Substitutions   int
+	Insertions      int
+	Deletions       int
+	ReferenceLength int
+	// Rate is (substitutions + insertions + deletions) / reference length
+	Rate float64
+	Ops  []Op
+}
+
+// Errors returns the number of substitutions, insertions and deletions
+func (r *Result) Errors() int {
+	return r.Substitutions + r.Insertions + r.Deletions
+}
+
+// WriteDiff writes the alignment errors, one per line, prefixed with S, I or D
+func (r *Result) WriteDiff(w io.Writer) error {
+	for _, op := range r.Ops {
+		var err error
+		switch op.Kind {
+		case OpSubstitution:
+			_, err = fmt.Fprintf(w, "S %q -> %q\n", op.Reference, op.Hypothesis)
+		case OpInsertion:
+			_, err = fmt.Fprintf(w, "I %q\n", op.Hypothesis)
+		case OpDeletion:
+			_, err = fmt.Fprintf(w, "D %q\n", op.Reference)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+	return nil
+}
+
+// WER computes the word error rate of hypothesis against reference
+func WER(reference, hypothesis string, n Normalization) *Result {
+	return Align(n.Words(reference), n.Words(hypothesis))
+}
+
+// CER computes the character error rate of hypothesis against reference.
+// Word boundaries are kept as single spaces.
+func CER(reference, hypothesis string, n Normalization) *Result {
+	return Align(characters(n.Words(reference)), characters(n.Words(hypothesis)))
+}
+
+// CompareTranscription computes the word error rate of a transcription against a reference text
+func CompareTranscription(data *gladia.TranscriptionData, reference string, n Normalization) *Result {
+	return WER(reference, Hypothesis(data), n)
+}
+
+// Hypothesis returns the text of a transcription, falling back to its utterances
+// when the full transcript is empty
+func Hypothesis(data *gladia.TranscriptionData) string {
+	if data.FullTranscript != "" {
+		return data.FullTranscript
+	}
+	texts := make([]string, 0, len(data.Utterances))
+	for _, u := range data.Utterances {
+		texts = append(texts, u.Text)
+	}
+	return strings.Join(texts, " ")
+}
+
+// LoadReference reads a plain text reference transcript
+func LoadReference(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read reference: %w", err)
+	}
+	return string(data), nil
+}
+
+// alignBlock is the largest number of cells aligned with a full cost matrix. Larger
+// inputs are split in half with Hirschberg's algorithm, so memory stays linear in the
+// length of the inputs.
+const alignBlock = 1 << 16
+
+// Align computes the minimum edit alignment between reference and hypothesis tokens
+func Align(reference, hypothesis []string) *Result {
+	ids := make(map[string]int)
+	intern := func(tokens []string) []int {
+		interned := make([]int, len(tokens))
+		for i, token := range tokens {
+			id, ok := ids[token]
+			if !ok {
+				id = len(ids)
+				ids[token] = id
+			}
+			interned[i] = id
+		}
+		return interned
+	}
+
+	a := &aligner{
+		reference:  reference,
+		hypothesis: hypothesis,
+		ref:        intern(reference),
+		hyp:        intern(hypothesis),
+	}
+	a.align(0, len(reference), 0, len(hypothesis))
+
+	result := &Result{ReferenceLength: len(reference), Ops: a.ops}
+	for _, op := range a.ops {
+		switch op.Kind {
+		case OpMatch:
+			result.Hits++
+		case OpSubstitution:
+			result.Substitutions++
+		case OpDeletion:
+			result.Deletions++
+		case OpInsertion:
+			result.Insertions++
+		}
+	}
+
+	if result.ReferenceLength > 0 {
+		result.Rate = float64(result.Errors()) / float64(result.ReferenceLength)
+	} else if result.Insertions > 0 {
+		result.Rate = 1
+	}
+	return result
+}
+
+// aligner aligns interned tokens, appending ops in order
+type aligner struct {
+	reference, hypothesis []string
+	ref, hyp              []int
+	ops                   []Op
+}
+
+// align appends the ops aligning ref[r0:r1] with hyp[h0:h1]
+func (a *aligner) align(r0, r1, h0, h1 int) {
+	cols := h1 - h0 + 1
+	if r1-r0 <= 1 || (r1-r0+1)*cols <= alignBlock {
+		a.alignFull(r0, r1, h0, h1)
+		return
+	}
+
+	// Split the reference in half and the hypothesis where the costs of aligning both
+	// halves add up to the least
+	mid := (r0 + r1) / 2
+	forward := a.costs(r0, mid, h0, h1, false)
+	backward := a.costs(mid, r1, h0, h1, true)
+	split, best := h0, -1
+	for k := range cols {
+		if cost := forward[k] + backward[cols-1-k]; best < 0 || cost < best {
+			split, best = h0+k, cost
+		}
+	}
+
+	a.align(r0, mid, h0, split)
+	a.align(mid, r1, split, h1)
+}
+
+// costs returns the edit distances between ref[r0:r1] and every prefix of hyp[h0:h1],
+// or every suffix when reverse is set, keeping only two rows of the cost matrix
+func (a *aligner) costs(r0, r1, h0, h1 int, reverse bool) []int {
+	cols := h1 - h0 + 1
+	previous := make([]int, cols)
+	current := make([]int, cols)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= r1-r0; i++ {
+		r := a.ref[r0+i-1]
+		if reverse {
+			r = a.ref[r1-i]
+		}
+		current[0] = i
+		for j := 1; j < cols; j++ {
+			h := a.hyp[h0+j-1]
+			if reverse {
+				h = a.hyp[h1-j]
+			}
+			substitution := previous[j-1]
+			if r != h {
+				substitution++
+			}
+			current[j] = min(substitution, previous[j]+1, current[j-1]+1)
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+// alignFull appends the ops aligning ref[r0:r1] with hyp[h0:h1] using the full cost matrix
+func (a *aligner) alignFull(r0, r1, h0, h1 int) {
+	ref, hyp := a.ref[r0:r1], a.hyp[h0:h1]
+	rows, cols := len(ref)+1, len(hyp)+1
+	cost := make([][]int, rows)
+	for i := range cost {
+		cost[i] = make([]int, cols)
+		cost[i][0] = i
+	}
+	for j := range cost[0] {
+		cost[0][j] = j
+	}
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			substitution := cost[i-1][j-1]
+			if ref[i-1] != hyp[j-1] {
+				substitution++
+			}
+			cost[i][j] = min(substitution, cost[i-1][j]+1, cost[i][j-1]+1)
+		}
+	}
+
+	first := len(a.ops)
+	for i, j := len(ref), len(hyp); i > 0 || j > 0; {
+		refIndex, hypIndex := r0+i-1, h0+j-1
+		switch {
+		case i > 0 && j > 0 && ref[i-1] == hyp[j-1] && cost[i][j] == cost[i-1][j-1]:
+			a.ops = append(a.ops, Op{Kind: OpMatch, Reference: a.reference[refIndex], Hypothesis: a.hypothesis[hypIndex], RefIndex: refIndex, HypIndex: hypIndex})
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && cost[i][j] == cost[i-1][j-1]+1:
+			a.ops = append(a.ops, Op{Kind: OpSubstitution, Reference: a.reference[refIndex], Hypothesis: a.hypothesis[hypIndex], RefIndex: refIndex, HypIndex: hypIndex})
+			i, j = i-1, j-1
+		case i > 0 && cost[i][j] == cost[i-1][j]+1:
+			a.ops = append(a.ops, Op{Kind: OpDeletion, Reference: a.reference[refIndex], RefIndex: refIndex, HypIndex: -1})
+			i--
+		default:
+			a.ops = append(a.ops, Op{Kind: OpInsertion, Hypothesis: a.hypothesis[hypIndex], RefIndex: -1, HypIndex: hypIndex})
+			j--
+		}
+	}
+
+	ops := a.ops[first:]
+	for left, right := 0, len(ops)-1; left < right; left, right = left+1, right-1 {
+		ops[left], ops[right] = ops[right], ops[left]
+	}
+}
+
+func characters(words []string) []string {
+	var chars []string
+	for i, word := range words {
+		if i > 0 {
+			chars = append(chars, " ")
+		}
+		for _, r := range word {
+			chars = append(chars, string(r))
+		}
+	}
+	return chars
+}
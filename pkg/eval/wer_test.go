@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// distance is the textbook full-matrix edit distance
+func distance(reference, hypothesis []string) int {
+	cost := make([][]int, len(reference)+1)
+	for i := range cost {
+		cost[i] = make([]int, len(hypothesis)+1)
+		cost[i][0] = i
+	}
+	for j := range cost[0] {
+		cost[0][j] = j
+	}
+	for i := 1; i <= len(reference); i++ {
+		for j := 1; j <= len(hypothesis); j++ {
+			substitution := cost[i-1][j-1]
+			if reference[i-1] != hypothesis[j-1] {
+				substitution++
+			}
+			cost[i][j] = min(substitution, cost[i-1][j]+1, cost[i][j-1]+1)
+		}
+	}
+	return cost[len(reference)][len(hypothesis)]
+}
+
+func randomWords(rng *rand.Rand, n, vocabulary int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = strconv.Itoa(rng.Intn(vocabulary))
+	}
+	return words
+}
+
+func TestAlign(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name       string
+		reference  []string
+		hypothesis []string
+	}{
+		{name: "empty", reference: nil, hypothesis: nil},
+		{name: "only insertions", reference: nil, hypothesis: []string{"a", "b"}},
+		{name: "only deletions", reference: []string{"a", "b"}, hypothesis: nil},
+		{name: "identical", reference: []string{"a", "b", "c"}, hypothesis: []string{"a", "b", "c"}},
+		{name: "small", reference: randomWords(rng, 40, 5), hypothesis: randomWords(rng, 35, 5)},
+		// Large enough to be split with Hirschberg's algorithm
+		{name: "split", reference: randomWords(rng, 700, 6), hypothesis: randomWords(rng, 650, 6)},
+		{name: "split uneven", reference: randomWords(rng, 900, 4), hypothesis: randomWords(rng, 80, 4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Align(tt.reference, tt.hypothesis)
+
+			if want := distance(tt.reference, tt.hypothesis); result.Errors() != want {
+				t.Errorf("errors = %d, want %d", result.Errors(), want)
+			}
+
+			// The ops must walk both sequences in order, each token exactly once
+			ref, hyp := 0, 0
+			for _, op := range result.Ops {
+				if op.Kind != OpInsertion {
+					if op.RefIndex != ref || op.Reference != tt.reference[ref] {
+						t.Fatalf("op %+v out of order at reference %d", op, ref)
+					}
+					ref++
+				}
+				if op.Kind != OpDeletion {
+					if op.HypIndex != hyp || op.Hypothesis != tt.hypothesis[hyp] {
+						t.Fatalf("op %+v out of order at hypothesis %d", op, hyp)
+					}
+					hyp++
+				}
+				if (op.Kind == OpMatch) != (op.Kind != OpInsertion && op.Kind != OpDeletion && op.Reference == op.Hypothesis) {
+					t.Fatalf("op %+v has the wrong kind", op)
+				}
+			}
+			if ref != len(tt.reference) || hyp != len(tt.hypothesis) {
+				t.Errorf("ops cover %d/%d reference and %d/%d hypothesis tokens",
+					ref, len(tt.reference), hyp, len(tt.hypothesis))
+			}
+			if result.Hits+result.Substitutions+result.Deletions != len(tt.reference) {
+				t.Errorf("counts %+v do not add up to the reference length", result)
+			}
+		})
+	}
+}
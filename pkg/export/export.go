@@ -0,0 +1,130 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Writer renders a transcription result as a document
+type Writer interface {
+	Write(w io.Writer, data *gladia.TranscriptionResultData) error
+}
+
+// Options configures how transcripts are rendered
+type Options struct {
+	// Title is used as document heading by the Markdown and HTML writers
+	Title string
+	// TimestampFormat formats a position in seconds, defaulting to FormatClock
+	TimestampFormat func(seconds float64) string
+	// Speakers merges and names diarized speakers
+	Speakers *gladia.SpeakerMap
+	// SpeakerName names a diarized speaker, taking precedence over Speakers
+	SpeakerName func(speaker int) string
+	// MergeParagraphs joins consecutive utterances of the same speaker
+	MergeParagraphs bool
+	// OmitTimestamps leaves timestamps out of the transcript
+	OmitTimestamps bool
+	// OmitSummary leaves the summary and chapters out of the document
+	OmitSummary bool
+}
+
+// NewWriter returns the writer for format, one of txt, md, html, jsonl, srt or vtt
+func NewWriter(format string, opts Options) (Writer, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "txt", "text":
+		return &TextWriter{Options: opts}, nil
+	case "md", "markdown":
+		return &MarkdownWriter{Options: opts}, nil
+	case "html", "htm":
+		return &HTMLWriter{Options: opts}, nil
+	case "jsonl", "jsonlines":
+		return &JSONLinesWriter{Options: opts}, nil
+	case "srt":
+		return &SRTWriter{Options: opts}, nil
+	case "vtt", "webvtt":
+		return &VTTWriter{Options: opts}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q", format)
+	}
+}
+
+// FormatClock formats seconds as HH:MM:SS
+func FormatClock(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// DefaultSpeakerName names speakers "Speaker N"
+func DefaultSpeakerName(speaker int) string {
+	return fmt.Sprintf("Speaker %d", speaker)
+}
+
+// Paragraph is a block of speech by a single speaker
+type Paragraph struct {
+	Speaker  int
+	Channel  int
+	Language string
+	Start    float64
+	End      float64
+	Text     string
+}
+
+func (o Options) timestamp(seconds float64) string {
+	if o.TimestampFormat != nil {
+		return o.TimestampFormat(seconds)
+	}
+	return FormatClock(seconds)
+}
+
+func (o Options) speaker(speaker int) string {
+	if o.SpeakerName != nil {
+		return o.SpeakerName(speaker)
+	}
+	if o.Speakers != nil {
+		return o.Speakers.Name(speaker)
+	}
+	return DefaultSpeakerName(speaker)
+}
+
+// Paragraphs turns the utterances of data into paragraphs, merging consecutive
+// utterances of the same speaker and channel when MergeParagraphs is set.
+// Speakers merged in the speaker map are reported under their merged ID.
+func (o Options) Paragraphs(data *gladia.TranscriptionResultData) []Paragraph {
+	var paragraphs []Paragraph
+	for _, u := range data.Transcription.Utterances {
+		u.Speaker = o.Speakers.Resolve(u.Speaker)
+		text := strings.TrimSpace(u.Text)
+		if n := len(paragraphs); o.MergeParagraphs && n > 0 {
+			last := &paragraphs[n-1]
+			if last.Speaker == u.Speaker && last.Channel == u.Channel {
+				last.End = u.End
+				last.Text += " " + text
+				continue
+			}
+		}
+		paragraphs = append(paragraphs, Paragraph{
+			Speaker:  u.Speaker,
+			Channel:  u.Channel,
+			Language: u.Language,
+			Start:    u.Start,
+			End:      u.End,
+			Text:     text,
+		})
+	}
+	return paragraphs
+}
+
+// summary returns the summary and chapters of data unless they are disabled
+func (o Options) summary(data *gladia.TranscriptionResultData) (string, []gladia.Chapter, error) {
+	if o.OmitSummary {
+		return "", nil, nil
+	}
+	chapters, err := data.ChapterList()
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(data.SummaryText()), chapters, nil
+}
@@ -0,0 +1,156 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func testData() *gladia.TranscriptionResultData {
+	data := &gladia.TranscriptionResultData{}
+	data.Transcription.Utterances = []gladia.Utterance{
+		{Speaker: 0, Start: 1, End: 2, Language: "en", Text: " Hello *everyone*. "},
+		{Speaker: 0, Start: 2, End: 4, Language: "en", Text: "Welcome to R&D."},
+		{Speaker: 1, Start: 65, End: 70, Language: "en", Text: "Thanks <all>."},
+	}
+	data.Summarization.Results = "A short meeting."
+	data.Chapters.Results = []any{map[string]any{"headline": "Intro", "summary": "Greetings", "start": 0.5}}
+	return data
+}
+
+func render(t *testing.T, format string, opts Options) string {
+	t.Helper()
+	writer, err := NewWriter(format, opts)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, testData()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.String()
+}
+
+func TestTextWriter(t *testing.T) {
+	got := render(t, "txt", Options{Title: "Weekly"})
+	want := `Weekly
+
+Summary
+
+A short meeting.
+
+Chapters
+
+[00:00:00] Intro
+
+[00:00:01] Speaker 0: Hello *everyone*.
+[00:00:02] Speaker 0: Welcome to R&D.
+[00:01:05] Speaker 1: Thanks <all>.
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	speakers := gladia.NewSpeakerMap()
+	speakers.SetName(1, "Ann")
+	got = render(t, "text", Options{MergeParagraphs: true, OmitTimestamps: true, OmitSummary: true, Speakers: speakers})
+	want = "Speaker 0: Hello *everyone*. Welcome to R&D.\n\nAnn: Thanks <all>.\n\n"
+	if got != want {
+		t.Errorf("merged got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	got := render(t, "md", Options{Title: "Team #1", MergeParagraphs: true})
+	for _, want := range []string{
+		"# Team \\#1\n\n",
+		"## Summary\n\nA short meeting.\n\n",
+		"- **00:00:00** Intro: Greetings\n",
+		"**Speaker 0** `00:00:01`: Hello \\*everyone\\*. Welcome to R&D.\n\n",
+		"**Speaker 1** `00:01:05`: Thanks \\<all>.\n\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, got)
+		}
+	}
+	if got := render(t, "markdown", Options{}); !strings.HasPrefix(got, "# Transcript\n") {
+		t.Errorf("untitled markdown starts with %q", strings.SplitN(got, "\n", 2)[0])
+	}
+}
+
+func TestHTMLWriter(t *testing.T) {
+	got := render(t, "html", Options{Title: "<Weekly>", SpeakerName: func(int) string { return "R&D" }})
+	for _, want := range []string{
+		"<title>&lt;Weekly&gt;</title>",
+		`<p class="summary">A short meeting.</p>`,
+		`<li><span class="time">00:00:00</span> <strong>Intro</strong>: Greetings</li>`,
+		`<span class="speaker">R&amp;D</span><span class="time">00:01:05</span><br>Thanks &lt;all&gt;.</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("html is missing %q:\n%s", want, got)
+		}
+	}
+	if got := render(t, "htm", Options{OmitTimestamps: true, OmitSummary: true}); strings.Contains(got, `class="time"`) || strings.Contains(got, "Summary") {
+		t.Errorf("html kept omitted sections:\n%s", got)
+	}
+}
+
+func TestJSONLinesWriter(t *testing.T) {
+	got := render(t, "jsonl", Options{MergeParagraphs: true})
+	var lines []Line
+	scanner := bufio.NewScanner(strings.NewReader(got))
+	for scanner.Scan() {
+		var line Line
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	want := []Line{
+		{Start: 1, End: 4, Timestamp: "00:00:01", Speaker: 0, SpeakerName: "Speaker 0", Language: "en", Text: "Hello *everyone*. Welcome to R&D."},
+		{Start: 65, End: 70, Timestamp: "00:01:05", Speaker: 1, SpeakerName: "Speaker 1", Language: "en", Text: "Thanks <all>."},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), got)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+	if strings.Contains(got, "A short meeting") {
+		t.Error("json lines include the summary")
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	result := &gladia.CompletedTranscriptionResult{ID: "job-1", Result: *testData()}
+
+	paths, err := WriteFiles(dir, "call", result, []string{"json", ".TXT"}, Options{})
+	if err != nil {
+		t.Fatalf("WriteFiles: %v", err)
+	}
+	want := []string{filepath.Join(dir, "call.json"), filepath.Join(dir, "call.txt")}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	data, err := os.ReadFile(want[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded gladia.CompletedTranscriptionResult
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.ID != "job-1" {
+		t.Errorf("json output = %s, %v", data, err)
+	}
+
+	if _, err := WriteFiles(dir, "call", result, []string{"txt", "docx"}, Options{}); err == nil {
+		t.Error("WriteFiles accepted an unsupported format")
+	}
+}
@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// WriteFiles writes the result to dir as name.<format> for each format. The json format
+// writes the complete result; the others are rendered by NewWriter. It returns the paths
+// of the written files.
+func WriteFiles(dir, name string, result *gladia.CompletedTranscriptionResult, formats []string, opts Options) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	paths := make([]string, 0, len(formats))
+	for _, format := range formats {
+		format = strings.ToLower(strings.TrimPrefix(format, "."))
+		buf := &bytes.Buffer{}
+		if format == "json" {
+			encoder := json.NewEncoder(buf)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				return paths, fmt.Errorf("failed to encode result: %w", err)
+			}
+		} else {
+			writer, err := NewWriter(format, opts)
+			if err != nil {
+				return paths, err
+			}
+			if err := writer.Write(buf, &result.Result); err != nil {
+				return paths, fmt.Errorf("failed to render %s: %w", format, err)
+			}
+		}
+
+		path := filepath.Join(dir, name+"."+format)
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %w", format, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,103 @@
+package export
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// HTMLWriter writes a self-contained HTML transcript with inline styles
+type HTMLWriter struct {
+	Options
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #222; }
+h1, h2 { font-weight: 600; }
+.chapters li { margin-bottom: .25rem; }
+.paragraph { margin: 0 0 1rem; }
+.speaker { font-weight: 600; }
+.time { color: #777; font-size: .85em; font-variant-numeric: tabular-nums; margin-left: .5rem; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .Summary}}
+<h2>Summary</h2>
+<p class="summary">{{.Summary}}</p>
+{{- end}}
+{{- if .Chapters}}
+<h2>Chapters</h2>
+<ul class="chapters">
+{{- range .Chapters}}
+<li><span class="time">{{.Time}}</span> <strong>{{.Headline}}</strong>{{if .Summary}}: {{.Summary}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<h2>Transcript</h2>
+{{- range .Paragraphs}}
+<p class="paragraph"><span class="speaker">{{.Speaker}}</span>{{if .Time}}<span class="time">{{.Time}}</span>{{end}}<br>{{.Text}}</p>
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlChapter struct {
+	Time     string
+	Headline string
+	Summary  string
+}
+
+type htmlParagraph struct {
+	Speaker string
+	Time    string
+	Text    string
+}
+
+// Write renders data as HTML
+func (h *HTMLWriter) Write(w io.Writer, data *gladia.TranscriptionResultData) error {
+	summary, chapters, err := h.summary(data)
+	if err != nil {
+		return err
+	}
+
+	page := struct {
+		Title      string
+		Summary    string
+		Chapters   []htmlChapter
+		Paragraphs []htmlParagraph
+	}{
+		Title:   h.Title,
+		Summary: summary,
+	}
+	if page.Title == "" {
+		page.Title = "Transcript"
+	}
+
+	for _, chapter := range chapters {
+		page.Chapters = append(page.Chapters, htmlChapter{
+			Time:     h.timestamp(chapter.Start),
+			Headline: chapter.Headline,
+			Summary:  chapter.Summary,
+		})
+	}
+	for _, p := range h.Paragraphs(data) {
+		paragraph := htmlParagraph{Speaker: h.speaker(p.Speaker), Text: p.Text}
+		if !h.OmitTimestamps {
+			paragraph.Time = h.timestamp(p.Start)
+		}
+		page.Paragraphs = append(page.Paragraphs, paragraph)
+	}
+
+	if err := htmlTemplate.Execute(w, page); err != nil {
+		return fmt.Errorf("failed to write html transcript: %w", err)
+	}
+	return nil
+}
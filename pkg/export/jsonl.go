@@ -0,0 +1,57 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// JSONLinesWriter writes one JSON object per utterance, or per paragraph when
+// MergeParagraphs is set. Summary and chapters are not included.
+type JSONLinesWriter struct {
+	Options
+}
+
+// Line is a single record written by JSONLinesWriter
+type Line struct {
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+	Speaker     int     `json:"speaker"`
+	SpeakerName string  `json:"speaker_name"`
+	Channel     int     `json:"channel"`
+	Language    string  `json:"language,omitempty"`
+	Text        string  `json:"text"`
+}
+
+// Write renders data as JSON-Lines
+func (j *JSONLinesWriter) Write(w io.Writer, data *gladia.TranscriptionResultData) error {
+	bw := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bw)
+
+	for _, p := range j.Paragraphs(data) {
+		line := Line{
+			Start:       p.Start,
+			End:         p.End,
+			Speaker:     p.Speaker,
+			SpeakerName: j.speaker(p.Speaker),
+			Channel:     p.Channel,
+			Language:    p.Language,
+			Text:        p.Text,
+		}
+		if !j.OmitTimestamps {
+			line.Timestamp = j.timestamp(p.Start)
+		}
+		if err := encoder.Encode(line); err != nil {
+			return fmt.Errorf("failed to encode utterance: %w", err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write json lines: %w", err)
+	}
+	return nil
+}
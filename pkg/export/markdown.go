@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// MarkdownWriter writes a Markdown transcript with summary and chapters sections
+type MarkdownWriter struct {
+	Options
+}
+
+// Write renders data as Markdown
+func (m *MarkdownWriter) Write(w io.Writer, data *gladia.TranscriptionResultData) error {
+	summary, chapters, err := m.summary(data)
+	if err != nil {
+		return err
+	}
+
+	title := m.Title
+	if title == "" {
+		title = "Transcript"
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", escapeMarkdown(title))
+	if summary != "" {
+		fmt.Fprintf(bw, "## Summary\n\n%s\n\n", summary)
+	}
+	if len(chapters) > 0 {
+		fmt.Fprint(bw, "## Chapters\n\n")
+		for _, chapter := range chapters {
+			fmt.Fprintf(bw, "- **%s** %s", m.timestamp(chapter.Start), escapeMarkdown(chapter.Headline))
+			if chapter.Summary != "" {
+				fmt.Fprintf(bw, ": %s", escapeMarkdown(chapter.Summary))
+			}
+			fmt.Fprint(bw, "\n")
+		}
+		fmt.Fprint(bw, "\n")
+	}
+
+	fmt.Fprint(bw, "## Transcript\n\n")
+	for _, p := range m.Paragraphs(data) {
+		fmt.Fprintf(bw, "**%s**", escapeMarkdown(m.speaker(p.Speaker)))
+		if !m.OmitTimestamps {
+			fmt.Fprintf(bw, " `%s`", m.timestamp(p.Start))
+		}
+		fmt.Fprintf(bw, ": %s\n\n", escapeMarkdown(p.Text))
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write markdown transcript: %w", err)
+	}
+	return nil
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"#", `\#`,
+	"<", `\<`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
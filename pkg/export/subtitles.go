@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// SRTWriter writes SubRip subtitles with one cue per paragraph, prefixed by the speaker name
+type SRTWriter struct {
+	Options
+}
+
+// Write renders data as SRT
+func (s *SRTWriter) Write(w io.Writer, data *gladia.TranscriptionResultData) error {
+	bw := bufio.NewWriter(w)
+	for i, p := range s.Paragraphs(data) {
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s: %s\n\n", i+1,
+			formatCueTime(p.Start, ","), formatCueTime(p.End, ","), s.speaker(p.Speaker), p.Text)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write srt subtitles: %w", err)
+	}
+	return nil
+}
+
+// vttEscaper escapes the characters WebVTT cue text and voice names reserve for markup
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// VTTWriter writes WebVTT subtitles with the speaker name as cue voice
+type VTTWriter struct {
+	Options
+}
+
+// Write renders data as WebVTT
+func (v *VTTWriter) Write(w io.Writer, data *gladia.TranscriptionResultData) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "WEBVTT\n\n")
+	for _, p := range v.Paragraphs(data) {
+		fmt.Fprintf(bw, "%s --> %s\n<v %s>%s\n\n",
+			formatCueTime(p.Start, "."), formatCueTime(p.End, "."),
+			vttEscaper.Replace(v.speaker(p.Speaker)), vttEscaper.Replace(p.Text))
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write vtt subtitles: %w", err)
+	}
+	return nil
+}
+
+// formatCueTime formats seconds as HH:MM:SS followed by sep and milliseconds
+func formatCueTime(seconds float64, sep string) string {
+	millis := int(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", millis/3600000, millis/60000%60, millis/1000%60, sep, millis%1000)
+}
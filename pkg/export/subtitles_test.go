@@ -0,0 +1,28 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func TestVTTEscapesMarkup(t *testing.T) {
+	data := &gladia.TranscriptionResultData{
+		Transcription: gladia.TranscriptionData{
+			Utterances: []gladia.Utterance{{Start: 0, End: 1.5, Text: "if a < b && b > c"}},
+		},
+	}
+	writer := &VTTWriter{Options{SpeakerName: func(int) string { return "R&D <lead>" }}}
+
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, data); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := "<v R&amp;D &lt;lead&gt;>if a &lt; b &amp;&amp; b &gt; c\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("got %q, want a cue containing %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// TextWriter writes a speaker-labeled plain text transcript
+type TextWriter struct {
+	Options
+}
+
+// Write renders data as plain text
+func (t *TextWriter) Write(w io.Writer, data *gladia.TranscriptionResultData) error {
+	summary, chapters, err := t.summary(data)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if t.Title != "" {
+		fmt.Fprintf(bw, "%s\n\n", t.Title)
+	}
+	if summary != "" {
+		fmt.Fprintf(bw, "Summary\n\n%s\n\n", summary)
+	}
+	if len(chapters) > 0 {
+		fmt.Fprint(bw, "Chapters\n\n")
+		for _, chapter := range chapters {
+			fmt.Fprintf(bw, "[%s] %s\n", t.timestamp(chapter.Start), chapter.Headline)
+		}
+		fmt.Fprint(bw, "\n")
+	}
+
+	for _, p := range t.Paragraphs(data) {
+		if t.OmitTimestamps {
+			fmt.Fprintf(bw, "%s: %s\n", t.speaker(p.Speaker), p.Text)
+		} else {
+			fmt.Fprintf(bw, "[%s] %s: %s\n", t.timestamp(p.Start), t.speaker(p.Speaker), p.Text)
+		}
+		if t.MergeParagraphs {
+			fmt.Fprint(bw, "\n")
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write text transcript: %w", err)
+	}
+	return nil
+}
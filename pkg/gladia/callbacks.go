@@ -0,0 +1,119 @@
+package gladia
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultCallbackRetention is how long a final event nobody was waiting for is kept
+const defaultCallbackRetention = 10 * time.Minute
+
+// Callbacks routes callback events to the WaitForTranscription calls waiting on them.
+// Feed it from the callback receiver, for example with webhook.NewHandler(callbacks.Handle, webhook.WithToken(token)),
+// and attach it to the client with WithCallbacks.
+type Callbacks struct {
+	mu        sync.Mutex
+	waiters   map[string][]chan *CallbackEvent
+	recent    map[string]retainedEvent
+	retention time.Duration
+	now       func() time.Time
+}
+
+type retainedEvent struct {
+	event    *CallbackEvent
+	received time.Time
+}
+
+// NewCallbacks creates an empty callback registry
+func NewCallbacks() *Callbacks {
+	return &Callbacks{
+		waiters:   make(map[string][]chan *CallbackEvent),
+		recent:    make(map[string]retainedEvent),
+		retention: defaultCallbackRetention,
+		now:       time.Now,
+	}
+}
+
+// Deliver completes the waiters of the event's transcription and reports whether there
+// were any. Final events arriving before anyone waits are kept for a while, so a callback
+// racing ahead of WaitForTranscription is not lost. Other events, and events that do not
+// name their transcription, are ignored.
+func (c *Callbacks) Deliver(event *CallbackEvent) bool {
+	if event.Event != EventTranscriptionSuccess && event.Event != EventTranscriptionError {
+		return false
+	}
+	id := event.ID
+	if id == "" && event.Payload != nil {
+		id = event.Payload.ID
+	}
+	if id == "" {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	waiters := c.waiters[id]
+	if len(waiters) == 0 {
+		c.prune()
+		c.recent[id] = retainedEvent{event: event, received: c.now()}
+		return false
+	}
+	for _, waiter := range waiters {
+		select {
+		case waiter <- event:
+		default:
+		}
+	}
+	return true
+}
+
+// Handle delivers the event; its signature matches webhook.HandlerFunc
+func (c *Callbacks) Handle(ctx context.Context, event *CallbackEvent) error {
+	c.Deliver(event)
+	return nil
+}
+
+// register returns a channel receiving the final event of a transcription and a
+// function to stop waiting
+func (c *Callbacks) register(transcriptionID string) (<-chan *CallbackEvent, func()) {
+	ch := make(chan *CallbackEvent, 1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if retained, ok := c.recent[transcriptionID]; ok {
+		delete(c.recent, transcriptionID)
+		ch <- retained.event
+	}
+	c.waiters[transcriptionID] = append(c.waiters[transcriptionID], ch)
+
+	return ch, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		waiters := c.waiters[transcriptionID]
+		for i, waiter := range waiters {
+			if waiter == ch {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(c.waiters, transcriptionID)
+		} else {
+			c.waiters[transcriptionID] = waiters
+		}
+	}
+}
+
+// prune drops retained events older than the retention period
+func (c *Callbacks) prune() {
+	now := c.now()
+	for id, retained := range c.recent {
+		if now.Sub(retained.received) > c.retention {
+			delete(c.recent, id)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package gladia
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+)
+
+// fakeAPI serves transcription status and deletion for a single job
+type fakeAPI struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	status       string
+	gets         int
+	deletes      int
+	deleteStatus int
+	deleteErr    error
+}
+
+func newFakeAPI(t *testing.T, status string) *fakeAPI {
+	t.Helper()
+	api := &fakeAPI{status: status, deleteStatus: http.StatusOK}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		id := strings.TrimPrefix(r.URL.Path, "/"+transcribeEndpoint)
+		switch r.Method {
+		case http.MethodGet:
+			api.gets++
+			json.NewEncoder(w).Encode(GetTranscriptionStatus{ID: id, Status: api.status})
+		case http.MethodDelete:
+			api.deletes++
+			api.deleteErr = r.Context().Err()
+			w.WriteHeader(api.deleteStatus)
+		}
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+func (a *fakeAPI) client(opts ...ClientOption) *Client {
+	return NewClient("key", append([]ClientOption{WithBaseURL(a.URL + "/")}, opts...)...)
+}
+
+func (a *fakeAPI) counts() (gets, deletes int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.gets, a.deletes
+}
+
+func TestCallbackResolvesWaiter(t *testing.T) {
+	api := newFakeAPI(t, StatusDone)
+	callbacks := NewCallbacks()
+	client := api.client(WithCallbacks(callbacks))
+
+	go func() {
+		for !callbacks.Deliver(&CallbackEvent{ID: "job-1", Event: EventTranscriptionSuccess}) {
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	result, err := client.WaitForTranscription(context.Background(), "job-1", WithPollInterval(time.Hour), WithCallbackTimeout(time.Hour))
+	if err != nil {
+		t.Fatalf("WaitForTranscription: %v", err)
+	}
+	if result.ID != "job-1" {
+		t.Errorf("result ID = %q", result.ID)
+	}
+	if gets, _ := api.counts(); gets != 1 {
+		t.Errorf("%d GET requests, want only the result fetch", gets)
+	}
+}
+
+func TestCallbackArrivingBeforeTheWait(t *testing.T) {
+	api := newFakeAPI(t, StatusProcessing)
+	callbacks := NewCallbacks()
+	client := api.client(WithCallbacks(callbacks))
+
+	code := http.StatusUnprocessableEntity
+	if callbacks.Deliver(&CallbackEvent{Event: EventTranscriptionError, Payload: &GetTranscriptionStatus{ID: "job-1", ErrorCode: &code}}) {
+		t.Fatal("Deliver reported a waiter before anyone waited")
+	}
+
+	_, err := client.WaitForTranscription(context.Background(), "job-1", WithPollInterval(time.Hour), WithCallbackTimeout(time.Hour))
+	if got, _ := gladiaerrors.StatusCode(err); got != code {
+		t.Errorf("error = %v, want status %d", err, code)
+	}
+	if gets, _ := api.counts(); gets != 0 {
+		t.Errorf("%d GET requests for a failed transcription", gets)
+	}
+}
+
+func TestPollingResumesAfterCallbackTimeout(t *testing.T) {
+	api := newFakeAPI(t, StatusDone)
+	client := api.client(WithCallbacks(NewCallbacks()))
+
+	start := time.Now()
+	result, err := client.WaitForTranscription(context.Background(), "job-1", WithPollInterval(10*time.Millisecond), WithCallbackTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("WaitForTranscription: %v", err)
+	}
+	if result.ID != "job-1" {
+		t.Errorf("result ID = %q", result.ID)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("polled after %v, before the callback timeout", elapsed)
+	}
+}
+
+func TestDeliverIgnoresEventsWithoutID(t *testing.T) {
+	callbacks := NewCallbacks()
+	ch, unregister := callbacks.register("")
+	defer unregister()
+
+	for _, event := range []*CallbackEvent{
+		{Event: EventTranscriptionSuccess},
+		{Event: EventTranscriptionSuccess, Payload: &GetTranscriptionStatus{}},
+		{ID: "job-1", Event: EventTranscriptionCreated},
+	} {
+		if callbacks.Deliver(event) {
+			t.Errorf("Deliver(%+v) reported a waiter", event)
+		}
+	}
+	select {
+	case event := <-ch:
+		t.Errorf("event %+v was delivered", event)
+	default:
+	}
+
+	callbacks.mu.Lock()
+	defer callbacks.mu.Unlock()
+	if len(callbacks.recent) != 0 {
+		t.Errorf("retained %d events, want none", len(callbacks.recent))
+	}
+}
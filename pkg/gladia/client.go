@@ -16,16 +16,18 @@ type HTTPDoer interface {
 
 // Client is the client for interacting with the Gladia API
 type Client struct {
-	APIKey     string
-	BaseURL    string
-	httpClient HTTPDoer
+	BaseURL     string
+	credentials CredentialProvider
+	httpClient  HTTPDoer
+	callbacks   *Callbacks
 }
 
-// NewClient creates a new Gladia API client
+// NewClient creates a new Gladia API client using apiKey, unless WithCredentialProvider
+// supplies the key instead
 func NewClient(apiKey string, opts ...ClientOption) *Client {
 	client := &Client{
-		APIKey:  apiKey,
-		BaseURL: defaultBaseURL,
+		BaseURL:     defaultBaseURL,
+		credentials: StaticKey(apiKey),
 		httpClient: &http.Client{
 			Timeout: defaultTimeout,
 		},
@@ -49,6 +51,14 @@ func WithBaseURL(url string) ClientOption {
 	}
 }
 
+// WithCredentialProvider sets where the API key comes from. The provider is consulted
+// before every request.
+func WithCredentialProvider(provider CredentialProvider) ClientOption {
+	return func(c *Client) {
+		c.credentials = provider
+	}
+}
+
 // WithHTTPClient sets the HTTP client for the client
 func WithHTTPClient(httpClient HTTPDoer) ClientOption {
 	return func(c *Client) {
@@ -64,3 +74,11 @@ func WithTimeout(timeout time.Duration) ClientOption {
 		}
 	}
 }
+
+// WithCallbacks makes WaitForTranscription complete from callbacks delivered to callbacks
+// instead of polling, as long as they arrive within the callback timeout
+func WithCallbacks(callbacks *Callbacks) ClientOption {
+	return func(c *Client) {
+		c.callbacks = callbacks
+	}
+}
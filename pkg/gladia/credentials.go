@@ -0,0 +1,180 @@
+package gladia
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialProvider supplies the API key. It is consulted before every request, so
+// providers can rotate keys without the client being rebuilt.
+type CredentialProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialProviderFunc adapts a function to a CredentialProvider
+type CredentialProviderFunc func(ctx context.Context) (string, error)
+
+// APIKey calls f
+func (f CredentialProviderFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticKey always supplies the same API key
+type StaticKey string
+
+// APIKey returns the key
+func (k StaticKey) APIKey(ctx context.Context) (string, error) {
+	return string(k), nil
+}
+
+// EnvKey supplies the API key held by an environment variable, read on every request
+type EnvKey string
+
+// APIKey returns the value of the variable
+func (k EnvKey) APIKey(ctx context.Context) (string, error) {
+	apiKey := strings.TrimSpace(os.Getenv(string(k)))
+	if apiKey == "" {
+		return "", fmt.Errorf("%s is not set", string(k))
+	}
+	return apiKey, nil
+}
+
+// FileKey supplies the API key stored in a file, such as a mounted Kubernetes secret.
+// The file is read again whenever its modification time or size changes.
+type FileKey struct {
+	path string
+
+	mu      sync.Mutex
+	apiKey  string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileKey creates a provider reading the API key from path
+func NewFileKey(path string) *FileKey {
+	return &FileKey{path: path}
+}
+
+// APIKey returns the key held by the file, re-reading it if it changed
+func (k *FileKey) APIKey(ctx context.Context) (string, error) {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat API key file: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.apiKey != "" && info.ModTime().Equal(k.modTime) && info.Size() == k.size {
+		return k.apiKey, nil
+	}
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read API key file: %w", err)
+	}
+	apiKey := strings.TrimSpace(string(data))
+	if apiKey == "" {
+		return "", errors.New("API key file is empty")
+	}
+	k.apiKey, k.modTime, k.size = apiKey, info.ModTime(), info.Size()
+	return apiKey, nil
+}
+
+// CommandKey supplies the API key printed by an external command, such as a secret
+// manager CLI. The output is cached for a while; failures are not cached. Concurrent
+// requests needing a fresh key share one run of the command.
+type CommandKey struct {
+	name string
+	args []string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	apiKey  string
+	fetched time.Time
+	refresh *keyRefresh
+}
+
+// keyRefresh is a run of the key command that other callers wait for
+type keyRefresh struct {
+	done   chan struct{}
+	apiKey string
+	err    error
+}
+
+// NewCommandKey creates a provider running name with args and caching its output for
+// ttl. A zero ttl caches the key for the life of the provider.
+func NewCommandKey(ttl time.Duration, name string, args ...string) *CommandKey {
+	return &CommandKey{name: name, args: args, ttl: ttl, now: time.Now}
+}
+
+// APIKey returns the cached key, running the command when the cache is empty or stale.
+// The command runs without the lock held, so callers with a cached key are not delayed.
+func (k *CommandKey) APIKey(ctx context.Context) (string, error) {
+	k.mu.Lock()
+	if k.apiKey != "" && (k.ttl <= 0 || k.now().Sub(k.fetched) < k.ttl) {
+		apiKey := k.apiKey
+		k.mu.Unlock()
+		return apiKey, nil
+	}
+	if r := k.refresh; r != nil {
+		k.mu.Unlock()
+		select {
+		case <-r.done:
+			return r.apiKey, r.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	r := &keyRefresh{done: make(chan struct{})}
+	k.refresh = r
+	k.mu.Unlock()
+
+	r.apiKey, r.err = k.run(ctx)
+
+	k.mu.Lock()
+	// A key invalidated while the command ran is not cached
+	if k.refresh == r {
+		k.refresh = nil
+		if r.err == nil {
+			k.apiKey, k.fetched = r.apiKey, k.now()
+		}
+	}
+	k.mu.Unlock()
+	close(r.done)
+	return r.apiKey, r.err
+}
+
+func (k *CommandKey) run(ctx context.Context) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, k.name, k.args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("failed to run API key command: %w: %s", err, message)
+		}
+		return "", fmt.Errorf("failed to run API key command: %w", err)
+	}
+	apiKey := strings.TrimSpace(string(out))
+	if apiKey == "" {
+		return "", errors.New("API key command printed nothing")
+	}
+	return apiKey, nil
+}
+
+// Invalidate drops the cached key so the next request runs the command again
+func (k *CommandKey) Invalidate() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.apiKey = ""
+	k.refresh = nil
+}
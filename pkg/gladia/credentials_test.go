@@ -0,0 +1,155 @@
+package gladia
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnvKey(t *testing.T) {
+	ctx := context.Background()
+	provider := EnvKey("GLADIA_TEST_KEY")
+
+	t.Setenv("GLADIA_TEST_KEY", " first\n")
+	if got, err := provider.APIKey(ctx); err != nil || got != "first" {
+		t.Errorf("APIKey = %q, %v, want first", got, err)
+	}
+	t.Setenv("GLADIA_TEST_KEY", "second")
+	if got, err := provider.APIKey(ctx); err != nil || got != "second" {
+		t.Errorf("APIKey = %q, %v, want the variable read again", got, err)
+	}
+	t.Setenv("GLADIA_TEST_KEY", "")
+	if _, err := provider.APIKey(ctx); err == nil || !strings.Contains(err.Error(), "GLADIA_TEST_KEY") {
+		t.Errorf("APIKey = %v, want an error naming the variable", err)
+	}
+}
+
+func TestFileKey(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "key")
+	provider := NewFileKey(path)
+
+	if _, err := provider.APIKey(ctx); err == nil {
+		t.Error("APIKey succeeded without a file")
+	}
+
+	write := func(key string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(key), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(want string) {
+		t.Helper()
+		if got, err := provider.APIKey(ctx); err != nil || got != want {
+			t.Errorf("APIKey = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	write("key-1\n", start)
+	check("key-1")
+
+	// Same size and time: the cached key is kept
+	write("key-2\n", start)
+	check("key-1")
+
+	write("key-3\n", start.Add(time.Second))
+	check("key-3")
+
+	write("longer-key\n", start.Add(time.Second))
+	check("longer-key")
+
+	write("\n", start.Add(2*time.Second))
+	if _, err := provider.APIKey(ctx); err == nil {
+		t.Error("APIKey accepted an empty file")
+	}
+}
+
+// counterCommand returns a shell command printing key-N, where N counts its runs
+func counterCommand(t *testing.T, delay string) (string, []string, func() int) {
+	t.Helper()
+	counter := filepath.Join(t.TempDir(), "runs")
+	script := `sleep ` + delay + `; echo run >> "$1"; echo "key-$(wc -l < "$1" | tr -d ' ')"`
+	runs := func() int {
+		data, _ := os.ReadFile(counter)
+		return strings.Count(string(data), "run")
+	}
+	return "sh", []string{"-c", script, "sh", counter}, runs
+}
+
+func TestCommandKey(t *testing.T) {
+	ctx := context.Background()
+	name, args, runs := counterCommand(t, "0")
+	provider := NewCommandKey(time.Minute, name, args...)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	check := func(want string) {
+		t.Helper()
+		if got, err := provider.APIKey(ctx); err != nil || got != want {
+			t.Errorf("APIKey = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	check("key-1")
+	now = now.Add(59 * time.Second)
+	check("key-1")
+	now = now.Add(time.Second)
+	check("key-2")
+	provider.Invalidate()
+	check("key-3")
+	if got := runs(); got != 3 {
+		t.Errorf("command ran %d times, want 3", got)
+	}
+
+	failing := NewCommandKey(0, "sh", "-c", "echo denied >&2; exit 1")
+	if _, err := failing.APIKey(ctx); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("APIKey = %v, want the command's stderr", err)
+	}
+	empty := NewCommandKey(0, "true")
+	if _, err := empty.APIKey(ctx); err == nil {
+		t.Error("APIKey accepted empty output")
+	}
+}
+
+func TestCommandKeySharesOneRun(t *testing.T) {
+	name, args, runs := counterCommand(t, "0.2")
+	provider := NewCommandKey(time.Minute, name, args...)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := provider.APIKey(context.Background()); err != nil || got != "key-1" {
+				t.Errorf("APIKey = %q, %v, want key-1", got, err)
+			}
+		}()
+	}
+
+	// A caller giving up does not wait for the command
+	for running := false; !running; {
+		provider.mu.Lock()
+		running = provider.refresh != nil
+		provider.mu.Unlock()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := provider.APIKey(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("APIKey = %v, want the caller's deadline", err)
+	}
+
+	wg.Wait()
+	if got := runs(); got != 1 {
+		t.Errorf("command ran %d times, want 1", got)
+	}
+}
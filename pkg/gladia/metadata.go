@@ -0,0 +1,86 @@
+package gladia
+
+import (
+	"fmt"
+	"math"
+)
+
+// CustomMetadata holds arbitrary key/value pairs attached to a transcription,
+// such as tenant or order IDs, and returned unchanged with its results
+type CustomMetadata map[string]any
+
+// Set stores value under key, allocating the map if it is nil
+func (m *CustomMetadata) Set(key string, value any) {
+	if *m == nil {
+		*m = make(CustomMetadata)
+	}
+	(*m)[key] = value
+}
+
+// Get returns the raw value stored under key
+func (m CustomMetadata) Get(key string) (any, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// String returns the value stored under key if it is a string
+func (m CustomMetadata) String(key string) (string, bool) {
+	value, ok := m[key].(string)
+	return value, ok
+}
+
+// Int returns the value stored under key if it is a whole number that fits in an int64.
+// Numbers read back from the API are decoded as float64 and converted.
+func (m CustomMetadata) Int(key string) (int64, bool) {
+	switch value := m[key].(type) {
+	case int:
+		return int64(value), true
+	case int64:
+		return value, true
+	case float64:
+		// -2^63 is exact as a float64 while 2^63-1 is not, so the upper bound is exclusive
+		if value != math.Trunc(value) || value < math.MinInt64 || value >= -math.MinInt64 {
+			return 0, false
+		}
+		return int64(value), true
+	default:
+		return 0, false
+	}
+}
+
+// Float returns the value stored under key if it is a number
+func (m CustomMetadata) Float(key string) (float64, bool) {
+	switch value := m[key].(type) {
+	case float64:
+		return value, true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
+
+// Bool returns the value stored under key if it is a boolean
+func (m CustomMetadata) Bool(key string) (bool, bool) {
+	value, ok := m[key].(bool)
+	return value, ok
+}
+
+// Matches reports whether every key in filter is present in m with an equal value.
+// Values are compared by their string representation, so 42 matches 42.0.
+func (m CustomMetadata) Matches(filter CustomMetadata) bool {
+	for key, want := range filter {
+		got, ok := m[key]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// SetMetadata stores value under key in the request's custom metadata
+func (r *TranscriptionRequest) SetMetadata(key string, value any) {
+	r.CustomMetadata.Set(key, value)
+}
@@ -0,0 +1,139 @@
+package gladia
+
+import (
+	"math"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCustomMetadataSet(t *testing.T) {
+	var m CustomMetadata
+	m.Set("tenant", "acme")
+	if got, ok := m.String("tenant"); !ok || got != "acme" {
+		t.Errorf("String = %q, %v", got, ok)
+	}
+
+	req := &TranscriptionRequest{}
+	req.SetMetadata("order", 7)
+	if got, ok := req.CustomMetadata.Int("order"); !ok || got != 7 {
+		t.Errorf("Int = %d, %v", got, ok)
+	}
+}
+
+func TestCustomMetadataGetters(t *testing.T) {
+	m := CustomMetadata{
+		"int":      42,
+		"int64":    int64(-3),
+		"whole":    42.0,
+		"fraction": 3.5,
+		"huge":     1e19,
+		"min":      float64(math.MinInt64),
+		"max":      math.Pow(2, 63),
+		"inf":      math.Inf(1),
+		"nan":      math.NaN(),
+		"text":     "42",
+		"flag":     true,
+	}
+
+	ints := []struct {
+		key  string
+		want int64
+		ok   bool
+	}{
+		{key: "int", want: 42, ok: true},
+		{key: "int64", want: -3, ok: true},
+		{key: "whole", want: 42, ok: true},
+		{key: "fraction"},
+		{key: "huge"},
+		{key: "min", want: math.MinInt64, ok: true},
+		{key: "max"},
+		{key: "inf"},
+		{key: "nan"},
+		{key: "text"},
+		{key: "missing"},
+	}
+	for _, tt := range ints {
+		t.Run("int "+tt.key, func(t *testing.T) {
+			got, ok := m.Int(tt.key)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("Int(%q) = %d, %v, want %d, %v", tt.key, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if got, ok := m.Float("int"); !ok || got != 42 {
+		t.Errorf("Float(int) = %v, %v", got, ok)
+	}
+	if _, ok := m.Float("text"); ok {
+		t.Error("Float accepted a string")
+	}
+	if got, ok := m.Bool("flag"); !ok || !got {
+		t.Errorf("Bool = %v, %v", got, ok)
+	}
+	if _, ok := m.String("int"); ok {
+		t.Error("String accepted a number")
+	}
+}
+
+func TestCustomMetadataMatches(t *testing.T) {
+	m := CustomMetadata{"tenant": "acme", "order": 42.0}
+	tests := []struct {
+		name   string
+		filter CustomMetadata
+		want   bool
+	}{
+		{name: "no filter", want: true},
+		{name: "equal", filter: CustomMetadata{"tenant": "acme"}, want: true},
+		{name: "number types", filter: CustomMetadata{"order": 42}, want: true},
+		{name: "different", filter: CustomMetadata{"tenant": "globex"}},
+		{name: "missing key", filter: CustomMetadata{"region": "eu"}},
+		{name: "all pairs", filter: CustomMetadata{"tenant": "acme", "order": 41}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Matches(tt.filter); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListOptionsQuery(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts ListOptions
+		want url.Values
+	}{
+		{name: "empty", want: url.Values{}},
+		{name: "paging", opts: ListOptions{Offset: 20, Limit: 10}, want: url.Values{"offset": {"20"}, "limit": {"10"}}},
+		{name: "date", opts: ListOptions{Date: at}, want: url.Values{"date": {"2026-03-01"}}},
+		{
+			name: "range",
+			opts: ListOptions{AfterDate: at, BeforeDate: at.Add(time.Hour)},
+			want: url.Values{"after_date": {"2026-03-01T09:30:00Z"}, "before_date": {"2026-03-01T10:30:00Z"}},
+		},
+		{name: "statuses", opts: ListOptions{Status: []string{StatusDone, StatusError}}, want: url.Values{"status": {"done", "error"}}},
+		{
+			name: "metadata",
+			opts: ListOptions{CustomMetadata: CustomMetadata{"tenant": "acme"}},
+			want: url.Values{"custom_metadata": {`{"tenant":"acme"}`}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.query()
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if got.Encode() != tt.want.Encode() {
+				t.Errorf("query = %s, want %s", got.Encode(), tt.want.Encode())
+			}
+		})
+	}
+
+	if _, err := (&ListOptions{CustomMetadata: CustomMetadata{"bad": make(chan int)}}).query(); err == nil {
+		t.Error("query encoded an unencodable metadata filter")
+	}
+}
@@ -2,6 +2,21 @@ package gladia
 
 import "time"
 
+// Transcription statuses reported by the Gladia API
+const (
+	StatusQueued     = "queued"
+	StatusProcessing = "processing"
+	StatusDone       = "done"
+	StatusError      = "error"
+)
+
+// Events sent to the callback URL of a transcription
+const (
+	EventTranscriptionCreated = "transcription.created"
+	EventTranscriptionSuccess = "transcription.success"
+	EventTranscriptionError   = "transcription.error"
+)
+
 // TranscriptionRequest represents a request to the Gladia transcription API
 type TranscriptionRequest struct {
 	AudioURL                 string                          `json:"audio_url"`
@@ -37,6 +52,7 @@ type TranscriptionRequest struct {
 	Sentences                bool                            `json:"sentences,omitempty"`
 	DisplayMode              bool                            `json:"display_mode,omitempty"`
 	PunctuationEnhanced      bool                            `json:"punctuation_enhanced,omitempty"`
+	CustomMetadata           CustomMetadata                  `json:"custom_metadata,omitempty"`
 }
 
 // DiarizationConfig contains settings for speaker diarization
@@ -267,7 +283,7 @@ type CompletedTranscriptionResult struct {
 	Status         string                  `json:"status"`
 	CreatedAt      time.Time               `json:"created_at"`
 	CompletedAt    time.Time               `json:"completed_at,omitempty"`
-	CustomMetadata map[string]any          `json:"custom_metadata,omitempty"`
+	CustomMetadata CustomMetadata          `json:"custom_metadata,omitempty"`
 	ErrorCode      int                     `json:"error_code,omitempty"`
 	Kind           string                  `json:"kind"`
 	File           GladiaFile              `json:"file"`
@@ -283,10 +299,17 @@ type GetTranscriptionStatus struct {
 	Status         string                   `json:"status"`
 	CreatedAt      time.Time                `json:"created_at"`
 	CompletedAt    *time.Time               `json:"completed_at,omitempty"`
-	CustomMetadata map[string]any           `json:"custom_metadata,omitempty"`
+	CustomMetadata CustomMetadata           `json:"custom_metadata,omitempty"`
 	ErrorCode      *int                     `json:"error_code,omitempty"`
 	Kind           string                   `json:"kind"`
 	File           *GladiaFile              `json:"file,omitempty"`
 	RequestParams  *TranscriptionRequest    `json:"request_params,omitempty"`
 	Result         *TranscriptionResultData `json:"result,omitempty"`
 }
+
+// CallbackEvent is the body Gladia posts to the callback URL of a transcription
+type CallbackEvent struct {
+	ID      string                  `json:"id"`
+	Event   string                  `json:"event"`
+	Payload *GetTranscriptionStatus `json:"payload,omitempty"`
+}
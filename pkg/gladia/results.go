@@ -0,0 +1,69 @@
+package gladia
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Chapter is a section of the audio produced by chapterization
+type Chapter struct {
+	Headline string   `json:"headline,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Gist     string   `json:"gist,omitempty"`
+	Keywords []string `json:"keywords,omitempty"`
+	Start    float64  `json:"start"`
+	End      float64  `json:"end"`
+}
+
+// SummaryText returns the summarization result, or an empty string if there is none
+func (d *TranscriptionResultData) SummaryText() string {
+	summary, _ := d.Summarization.Results.(string)
+	return summary
+}
+
+// ChapterList decodes the chapterization results
+func (d *TranscriptionResultData) ChapterList() ([]Chapter, error) {
+	var chapters []Chapter
+	if err := decodeResults(d.Chapters.Results, &chapters); err != nil {
+		return nil, fmt.Errorf("failed to decode chapters: %w", err)
+	}
+	return chapters, nil
+}
+
+// decodeResults converts the untyped results of a ProcessingResult into v
+func decodeResults(results any, v any) error {
+	if results == nil {
+		return nil
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// NamedEntity is an entity found by named entity recognition or name consistency
+type NamedEntity struct {
+	EntityType string  `json:"entity_type"`
+	Text       string  `json:"text"`
+	Start      float64 `json:"start,omitempty"`
+	End        float64 `json:"end,omitempty"`
+}
+
+// NamedEntities decodes the named entity recognition results
+func (d *TranscriptionResultData) NamedEntities() ([]NamedEntity, error) {
+	var entities []NamedEntity
+	if err := decodeResults(d.NamedEntityRecognition.Results, &entities); err != nil {
+		return nil, fmt.Errorf("failed to decode named entities: %w", err)
+	}
+	return entities, nil
+}
+
+// Translations decodes the translation results, one per target language
+func (d *TranscriptionResultData) Translations() ([]TranslationResult, error) {
+	var translations []TranslationResult
+	if err := decodeResults(d.Translation.Results, &translations); err != nil {
+		return nil, fmt.Errorf("failed to decode translations: %w", err)
+	}
+	return translations, nil
+}
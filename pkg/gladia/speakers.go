@@ -0,0 +1,167 @@
+package gladia
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// introduction matches the words people use right before saying their own name
+var introduction = regexp.MustCompile(`(?i)\b(my name is|my name's|i am|i'm|this is|it's|call me)\s*$`)
+
+// SpeakerMap names diarized speakers and merges speaker IDs that diarization split apart
+type SpeakerMap struct {
+	// Names maps a speaker ID to a display name
+	Names map[int]string `json:"names,omitempty"`
+	// Merges maps a speaker ID to the ID it was merged into
+	Merges map[int]int `json:"merges,omitempty"`
+}
+
+// NewSpeakerMap creates an empty speaker map
+func NewSpeakerMap() *SpeakerMap {
+	return &SpeakerMap{
+		Names:  make(map[int]string),
+		Merges: make(map[int]int),
+	}
+}
+
+// SetName names a speaker. Names set on merged speakers apply to the speaker they were merged into.
+func (m *SpeakerMap) SetName(speaker int, name string) {
+	if m.Names == nil {
+		m.Names = make(map[int]string)
+	}
+	m.Names[m.Resolve(speaker)] = name
+}
+
+// Merge folds speaker from into speaker into, keeping into's name if it has one
+func (m *SpeakerMap) Merge(from, into int) error {
+	from, into = m.Resolve(from), m.Resolve(into)
+	if from == into {
+		return fmt.Errorf("speaker %d is already merged into speaker %d", from, into)
+	}
+
+	if m.Merges == nil {
+		m.Merges = make(map[int]int)
+	}
+	m.Merges[from] = into
+
+	if name, ok := m.Names[from]; ok {
+		if _, named := m.Names[into]; !named {
+			m.Names[into] = name
+		}
+		delete(m.Names, from)
+	}
+	return nil
+}
+
+// Resolve returns the ID a speaker ends up as after merges
+func (m *SpeakerMap) Resolve(speaker int) int {
+	if m == nil {
+		return speaker
+	}
+	for seen := 0; seen <= len(m.Merges); seen++ {
+		into, ok := m.Merges[speaker]
+		if !ok {
+			break
+		}
+		speaker = into
+	}
+	return speaker
+}
+
+// Name returns the display name of a speaker, or "Speaker N" if it has none
+func (m *SpeakerMap) Name(speaker int) string {
+	speaker = m.Resolve(speaker)
+	if m != nil {
+		if name, ok := m.Names[speaker]; ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("Speaker %d", speaker)
+}
+
+// Apply rewrites the speaker of every utterance in data to its merged ID
+func (m *SpeakerMap) Apply(data *TranscriptionResultData) {
+	for i := range data.Transcription.Utterances {
+		u := &data.Transcription.Utterances[i]
+		u.Speaker = m.Resolve(u.Speaker)
+	}
+}
+
+// InferNames names speakers who introduce themselves ("my name is ...", "I'm ...") with a
+// person found by named entity recognition or name consistency. Speakers that already have a
+// name are left alone. It returns the number of speakers it named.
+func (m *SpeakerMap) InferNames(data *TranscriptionResultData) (int, error) {
+	entities, err := data.NamedEntities()
+	if err != nil {
+		return 0, err
+	}
+	var consistent []NamedEntity
+	if err := decodeResults(data.NameConsistency.Results, &consistent); err == nil {
+		entities = append(entities, consistent...)
+	}
+
+	var people []string
+	for _, entity := range entities {
+		kind := strings.ToUpper(entity.EntityType)
+		if entity.Text != "" && (kind == "PER" || strings.Contains(kind, "PERSON") || strings.Contains(kind, "NAME")) {
+			people = append(people, entity.Text)
+		}
+	}
+
+	named := 0
+	for _, u := range data.Transcription.Utterances {
+		speaker := m.Resolve(u.Speaker)
+		if _, ok := m.Names[speaker]; ok {
+			continue
+		}
+		for _, person := range people {
+			if name, ok := introduces(u.Text, person); ok {
+				m.SetName(speaker, name)
+				named++
+				break
+			}
+		}
+	}
+	return named, nil
+}
+
+// introduces reports whether text contains person as whole words right after an
+// introduction phrase, returning the name as it is written in text
+func introduces(text, person string) (string, bool) {
+	lower, target := strings.ToLower(text), strings.ToLower(person)
+	if target == "" {
+		return "", false
+	}
+	for offset := 0; ; {
+		i := strings.Index(lower[offset:], target)
+		if i < 0 {
+			return "", false
+		}
+		start := offset + i
+		end := start + len(target)
+		if wordBoundary(lower, start) && wordBoundary(lower, end) && introduction.MatchString(lower[:start]) {
+			if len(lower) == len(text) {
+				return text[start : start+len(target)], true
+			}
+			return person, true
+		}
+		offset = start + len(target)
+	}
+}
+
+// wordBoundary reports whether i in s does not fall inside a word
+func wordBoundary(s string, i int) bool {
+	if i == 0 || i == len(s) {
+		return true
+	}
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i:])
+	return !isWordRune(before) || !isWordRune(after)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
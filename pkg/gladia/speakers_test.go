@@ -0,0 +1,89 @@
+package gladia
+
+import "testing"
+
+func TestIntroduces(t *testing.T) {
+	tests := []struct {
+		text   string
+		person string
+		want   string
+		ok     bool
+	}{
+		{text: "Hi, my name is Ann.", person: "ann", want: "Ann", ok: true},
+		{text: "I'm Ann's manager", person: "Ann", want: "Ann", ok: true},
+		{text: "This is Ann Marie speaking", person: "Ann Marie", want: "Ann Marie", ok: true},
+		{text: "This is Annual planning", person: "Ann", ok: false},
+		{text: "It's Joanne here", person: "Ann", ok: false},
+		{text: "I am the annual host, I'm Ann", person: "Ann", want: "Ann", ok: true},
+		{text: "Ann is here", person: "Ann", ok: false},
+		{text: "this isAnn", person: "Ann", ok: false},
+		{text: "my name is ", person: "", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := introduces(tt.text, tt.person)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("introduces(%q, %q) = %q, %v, want %q, %v", tt.text, tt.person, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestInferNames(t *testing.T) {
+	data := &TranscriptionResultData{}
+	data.Transcription.Utterances = []Utterance{
+		{Speaker: 0, Text: "Welcome to the annual review."},
+		{Speaker: 1, Text: "Thanks, I'm Ann from finance."},
+		{Speaker: 2, Text: "And my name is Bob."},
+		{Speaker: 3, Text: "Call me Bob too."},
+	}
+	data.NamedEntityRecognition.Results = []any{
+		map[string]any{"entity_type": "PER", "text": "Ann"},
+		map[string]any{"entity_type": "ORG", "text": "finance"},
+	}
+	data.NameConsistency.Results = []any{
+		map[string]any{"entity_type": "PERSON_NAME", "text": "Bob"},
+	}
+
+	m := NewSpeakerMap()
+	m.SetName(3, "Robert")
+	named, err := m.InferNames(data)
+	if err != nil {
+		t.Fatalf("InferNames: %v", err)
+	}
+	if named != 2 {
+		t.Errorf("named %d speakers, want 2", named)
+	}
+	for speaker, want := range map[int]string{0: "Speaker 0", 1: "Ann", 2: "Bob", 3: "Robert"} {
+		if got := m.Name(speaker); got != want {
+			t.Errorf("speaker %d = %q, want %q", speaker, got, want)
+		}
+	}
+}
+
+func TestSpeakerMapMerge(t *testing.T) {
+	m := NewSpeakerMap()
+	m.SetName(2, "Ann")
+	if err := m.Merge(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Merge(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Merge(2, 0); err == nil {
+		t.Error("merging a speaker into itself succeeded")
+	}
+	if got := m.Name(2); got != "Ann" {
+		t.Errorf("merged speaker name = %q, want Ann", got)
+	}
+
+	data := &TranscriptionResultData{}
+	data.Transcription.Utterances = []Utterance{{Speaker: 2}, {Speaker: 3}}
+	m.Apply(data)
+	if got := data.Transcription.Utterances[0].Speaker; got != 0 {
+		t.Errorf("applied speaker = %d, want 0", got)
+	}
+	if got := data.Transcription.Utterances[1].Speaker; got != 3 {
+		t.Errorf("unmerged speaker = %d, want 3", got)
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
 )
 
 const uploadEndpoint = "v2/upload"
@@ -31,6 +38,57 @@ type AudioMetadata struct {
 	NumberOfChannels int     `json:"number_of_channels"`
 }
 
+// ListOptions filters and paginates ListTranscriptions
+type ListOptions struct {
+	Offset int
+	Limit  int
+	// Date restricts results to transcriptions created on that day
+	Date       time.Time
+	BeforeDate time.Time
+	AfterDate  time.Time
+	Status     []string
+	// CustomMetadata restricts results to transcriptions whose metadata contains these pairs
+	CustomMetadata CustomMetadata
+}
+
+func (o *ListOptions) query() (url.Values, error) {
+	query := url.Values{}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if !o.Date.IsZero() {
+		query.Set("date", o.Date.Format(time.DateOnly))
+	}
+	if !o.BeforeDate.IsZero() {
+		query.Set("before_date", o.BeforeDate.Format(time.RFC3339))
+	}
+	if !o.AfterDate.IsZero() {
+		query.Set("after_date", o.AfterDate.Format(time.RFC3339))
+	}
+	for _, status := range o.Status {
+		query.Add("status", status)
+	}
+	if len(o.CustomMetadata) > 0 {
+		metadata, err := json.Marshal(o.CustomMetadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal custom metadata filter: %w", err)
+		}
+		query.Set("custom_metadata", string(metadata))
+	}
+	return query, nil
+}
+
+// TranscriptionList is a page of transcriptions returned by ListTranscriptions
+type TranscriptionList struct {
+	First   string                   `json:"first"`
+	Current string                   `json:"current"`
+	Next    string                   `json:"next,omitempty"`
+	Items   []GetTranscriptionStatus `json:"items"`
+}
+
 // UploadFile uploads an audio file to Gladia API and returns the audio URL that can be used for transcription
 func (c *Client) UploadFile(ctx context.Context, filePath string) (*UploadResponse, error) {
 	file, err := os.Open(filePath)
@@ -39,33 +97,40 @@ func (c *Client) UploadFile(ctx context.Context, filePath string) (*UploadRespon
 	}
 	defer file.Close()
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	return c.UploadReader(ctx, filepath.Base(filePath), file)
+}
 
-	part, err := writer.CreateFormFile("audio", filepath.Base(filePath))
-	if err != nil {
+// UploadReader uploads audio read from r under the given file name. The audio is
+// streamed, not buffered, so r is read while the request is sent.
+func (c *Client) UploadReader(ctx context.Context, filename string, r io.Reader) (*UploadResponse, error) {
+	// The multipart framing around the audio is written up front so only the audio
+	// itself is streamed
+	framing := &bytes.Buffer{}
+	writer := multipart.NewWriter(framing)
+	if _, err := writer.CreateFormFile("audio", filename); err != nil {
 		return nil, fmt.Errorf("failed to create form file: %w", err)
 	}
-
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
+	header := bytes.Clone(framing.Bytes())
+	framing.Reset()
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
 	}
+	trailer := framing.Bytes()
 
-	err = writer.Close()
-	if err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	body := io.MultiReader(bytes.NewReader(header), r, bytes.NewReader(trailer))
+	length := int64(-1)
+	if size, ok := readerSize(r); ok {
+		length = int64(len(header)) + size + int64(len(trailer))
 	}
 
-	resp, err := c.sendFormRequest(ctx, uploadEndpoint, body, writer.FormDataContentType())
+	resp, err := c.sendFormRequest(ctx, uploadEndpoint, body, length, writer.FormDataContentType())
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 && resp.StatusCode != 201 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("received non-200 response: %s, body: %s", resp.Status, string(bodyBytes))
+		return nil, newResponseError(resp)
 	}
 
 	var uploadResponse UploadResponse
@@ -76,11 +141,34 @@ func (c *Client) UploadFile(ctx context.Context, filePath string) (*UploadRespon
 	return &uploadResponse, nil
 }
 
+// readerSize returns the number of bytes left in r when it can be known without reading
+func readerSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - offset, true
+	}
+	return 0, false
+}
+
 func (s *Client) Transcribe(ctx context.Context, audioURL string) (*TranscriptionResponse, error) {
-	reqBody := TranscriptionRequest{AudioURL: audioURL}
+	return s.TranscribeWithRequest(ctx, &TranscriptionRequest{AudioURL: audioURL})
+}
+
+// TranscribeWithRequest starts a transcription configured by req
+func (c *Client) TranscribeWithRequest(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
 	var result TranscriptionResponse
 
-	err := s.sendJSONRequest(ctx, http.MethodPost, transcribeEndpoint, reqBody, &result)
+	err := c.sendJSONRequest(ctx, http.MethodPost, transcribeEndpoint, req, &result)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +176,28 @@ func (s *Client) Transcribe(ctx context.Context, audioURL string) (*Transcriptio
 	return &result, nil
 }
 
+// ListTranscriptions lists pre-recorded transcriptions, newest first, filtered by opts
+func (c *Client) ListTranscriptions(ctx context.Context, opts *ListOptions) (*TranscriptionList, error) {
+	endpoint := strings.TrimSuffix(transcribeEndpoint, "/")
+	if opts != nil {
+		query, err := opts.query()
+		if err != nil {
+			return nil, err
+		}
+		if encoded := query.Encode(); encoded != "" {
+			endpoint += "?" + encoded
+		}
+	}
+
+	var list TranscriptionList
+	err := c.sendJSONRequest(ctx, http.MethodGet, endpoint, nil, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
 // GetTranscriptionResult retrieves the result of a transcription by its ID
 func (c *Client) GetTranscriptionResult(ctx context.Context, transcriptionID string) (*CompletedTranscriptionResult, error) {
 	var result CompletedTranscriptionResult
@@ -140,7 +250,9 @@ func (c *Client) sendJSONRequest(ctx context.Context, method, endpoint string, r
 	if reqBody != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
-	req.Header.Set(gladiaHeaderKey, c.APIKey)
+	if err := c.authorize(ctx, req); err != nil {
+		return err
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -149,8 +261,7 @@ func (c *Client) sendJSONRequest(ctx context.Context, method, endpoint string, r
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("received non-200 response: %s, body: %s", resp.Status, string(bodyBytes))
+		return newResponseError(resp)
 	}
 
 	if respBody != nil {
@@ -162,15 +273,19 @@ func (c *Client) sendJSONRequest(ctx context.Context, method, endpoint string, r
 	return nil
 }
 
-// sendFormRequest sends a multipart form request to the Gladia API
-func (c *Client) sendFormRequest(ctx context.Context, endpoint string, formData *bytes.Buffer, contentType string) (*http.Response, error) {
+// sendFormRequest sends a multipart form request to the Gladia API. A negative length
+// sends the body with chunked encoding.
+func (c *Client) sendFormRequest(ctx context.Context, endpoint string, formData io.Reader, length int64, contentType string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+endpoint, formData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.ContentLength = length
 
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set(gladiaHeaderKey, c.APIKey)
+	if err := c.authorize(ctx, req); err != nil {
+		return nil, err
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -179,3 +294,22 @@ func (c *Client) sendFormRequest(ctx context.Context, endpoint string, formData
 
 	return resp, nil
 }
+
+// authorize sets the API key supplied by the credential provider on req
+func (c *Client) authorize(ctx context.Context, req *http.Request) error {
+	if c.credentials == nil {
+		return errors.New("no credential provider configured")
+	}
+	apiKey, err := c.credentials.APIKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get API key: %w", err)
+	}
+	req.Header.Set(gladiaHeaderKey, apiKey)
+	return nil
+}
+
+// newResponseError builds an error carrying the HTTP status code of a failed response
+func newResponseError(resp *http.Response) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	return gladiaerrors.New(resp.StatusCode, fmt.Sprintf("received non-200 response: %s, body: %s", resp.Status, string(bodyBytes)))
+}
@@ -0,0 +1,163 @@
+package gladia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+)
+
+const defaultPollInterval = 3 * time.Second
+const defaultCallbackTimeout = 30 * time.Second
+
+// cancelRemoteTimeout bounds the cleanup call made when a wait is abandoned
+const cancelRemoteTimeout = 10 * time.Second
+
+type waitConfig struct {
+	pollInterval    time.Duration
+	callbackTimeout time.Duration
+	cancelRemote    bool
+}
+
+// WaitOption configures WaitForTranscription
+type WaitOption func(*waitConfig)
+
+// WithPollInterval sets how often the transcription status is checked
+func WithPollInterval(interval time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.pollInterval = interval
+	}
+}
+
+// WithCallbackTimeout sets how long WaitForTranscription waits for a callback before it
+// starts polling. It only applies to clients created with WithCallbacks; zero polls at once.
+func WithCallbackTimeout(timeout time.Duration) WaitOption {
+	return func(c *waitConfig) {
+		c.callbackTimeout = timeout
+	}
+}
+
+// WithCancelRemoteOnContextDone deletes the transcription when ctx ends before it
+// finishes, so an abandoned job stops running and billing. The deletion runs with its own
+// short timeout; if it fails, its error is joined to the context error.
+func WithCancelRemoteOnContextDone() WaitOption {
+	return func(c *waitConfig) {
+		c.cancelRemote = true
+	}
+}
+
+// Poller makes the API calls of a wait. Wrappers of a Client, such as instrumentation,
+// implement it to see every call made while waiting.
+type Poller interface {
+	GetTranscriptionStatus(ctx context.Context, transcriptionID string) (*GetTranscriptionStatus, error)
+	GetTranscriptionResult(ctx context.Context, transcriptionID string) (*CompletedTranscriptionResult, error)
+	DeleteTranscription(ctx context.Context, transcriptionID string) error
+}
+
+// WaitForTranscription waits until a transcription is done and returns its result.
+// A transcription that ends in the error status is reported as an error carrying its error code.
+// Clients created with WithCallbacks wait for the callback first and fall back to polling
+// the status after the callback timeout; otherwise the status is polled from the start.
+func (c *Client) WaitForTranscription(ctx context.Context, transcriptionID string, opts ...WaitOption) (*CompletedTranscriptionResult, error) {
+	return c.WaitForTranscriptionUsing(ctx, c, transcriptionID, opts...)
+}
+
+// WaitForTranscriptionUsing is WaitForTranscription making its API calls through poller,
+// while callbacks are still received through c
+func (c *Client) WaitForTranscriptionUsing(ctx context.Context, poller Poller, transcriptionID string, opts ...WaitOption) (*CompletedTranscriptionResult, error) {
+	cfg := waitConfig{pollInterval: defaultPollInterval, callbackTimeout: defaultCallbackTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	result, finished, err := c.wait(ctx, poller, transcriptionID, cfg)
+	if err != nil && !finished && cfg.cancelRemote && ctx.Err() != nil {
+		if cancelErr := cancelRemote(ctx, poller, transcriptionID); cancelErr != nil {
+			err = errors.Join(err, cancelErr)
+		}
+	}
+	return result, err
+}
+
+// wait returns the result of a transcription and whether it reached a final status
+func (c *Client) wait(ctx context.Context, poller Poller, transcriptionID string, cfg waitConfig) (*CompletedTranscriptionResult, bool, error) {
+	var events <-chan *CallbackEvent
+	var fallback <-chan time.Time
+	if c.callbacks != nil {
+		ch, unregister := c.callbacks.register(transcriptionID)
+		defer unregister()
+		events = ch
+
+		timer := time.NewTimer(cfg.callbackTimeout)
+		defer timer.Stop()
+		fallback = timer.C
+	}
+
+	ticker := time.NewTicker(cfg.pollInterval)
+	defer ticker.Stop()
+	polling := events == nil
+	var ticks <-chan time.Time
+	if polling {
+		ticks = ticker.C
+	}
+
+	for {
+		if polling {
+			status, err := poller.GetTranscriptionStatus(ctx, transcriptionID)
+			if err != nil {
+				return nil, false, err
+			}
+
+			switch status.Status {
+			case StatusDone:
+				result, err := poller.GetTranscriptionResult(ctx, transcriptionID)
+				return result, true, err
+			case StatusError:
+				return nil, true, transcriptionError(status)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case event := <-events:
+			if event.Event == EventTranscriptionError {
+				status := event.Payload
+				if status == nil {
+					status = &GetTranscriptionStatus{ID: transcriptionID}
+				}
+				return nil, true, transcriptionError(status)
+			}
+			result, err := poller.GetTranscriptionResult(ctx, transcriptionID)
+			return result, true, err
+		case <-fallback:
+			polling, fallback = true, nil
+			ticker.Reset(cfg.pollInterval)
+			ticks = ticker.C
+		case <-ticks:
+		}
+	}
+}
+
+// cancelRemote deletes an abandoned transcription with a context detached from ctx
+func cancelRemote(ctx context.Context, poller Poller, transcriptionID string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelRemoteTimeout)
+	defer cancel()
+
+	if err := poller.DeleteTranscription(ctx, transcriptionID); err != nil {
+		return fmt.Errorf("failed to cancel transcription %s: %w", transcriptionID, err)
+	}
+	return nil
+}
+
+// transcriptionError describes a transcription that ended in the error status
+func transcriptionError(status *GetTranscriptionStatus) error {
+	code := http.StatusInternalServerError
+	if status.ErrorCode != nil {
+		code = *status.ErrorCode
+	}
+	return gladiaerrors.New(code, fmt.Sprintf("transcription %s failed", status.ID))
+}
@@ -0,0 +1,76 @@
+package gladia
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCancelRemoteOnContextDone(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []WaitOption
+		deleteStatus int
+		wantDeletes  int
+		wantErr      string
+	}{
+		{name: "without the option", wantDeletes: 0},
+		{name: "deleted", opts: []WaitOption{WithCancelRemoteOnContextDone()}, deleteStatus: http.StatusOK, wantDeletes: 1},
+		{
+			name:         "delete fails",
+			opts:         []WaitOption{WithCancelRemoteOnContextDone()},
+			deleteStatus: http.StatusInternalServerError,
+			wantDeletes:  1,
+			wantErr:      "failed to cancel transcription job-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t, StatusProcessing)
+			api.deleteStatus = tt.deleteStatus
+			client := api.client()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+			defer cancel()
+			opts := append([]WaitOption{WithPollInterval(5 * time.Millisecond)}, tt.opts...)
+			_, err := client.WaitForTranscription(ctx, "job-1", opts...)
+
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error = %v, want the context error", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want it to report %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" && err != nil && strings.Contains(err.Error(), "failed to cancel") {
+				t.Errorf("error = %v, want no cancellation failure", err)
+			}
+
+			_, deletes := api.counts()
+			if deletes != tt.wantDeletes {
+				t.Errorf("%d DELETE requests, want %d", deletes, tt.wantDeletes)
+			}
+			api.mu.Lock()
+			deleteErr := api.deleteErr
+			api.mu.Unlock()
+			if deleteErr != nil {
+				t.Errorf("DELETE was sent on a cancelled context: %v", deleteErr)
+			}
+		})
+	}
+}
+
+func TestFinishedTranscriptionIsNotCancelled(t *testing.T) {
+	api := newFakeAPI(t, StatusError)
+	client := api.client()
+
+	_, err := client.WaitForTranscription(context.Background(), "job-1", WithPollInterval(5*time.Millisecond), WithCancelRemoteOnContextDone())
+	if err == nil {
+		t.Fatal("expected the transcription error")
+	}
+	if _, deletes := api.counts(); deletes != 0 {
+		t.Errorf("%d DELETE requests for a finished transcription", deletes)
+	}
+}
@@ -0,0 +1,100 @@
+package gladiatest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Fault makes the server misbehave on matching requests
+type Fault struct {
+	// Method restricts the fault to one HTTP method; empty matches any
+	Method string
+	// Path restricts the fault to request paths with this prefix; empty matches any
+	Path string
+	// Delay holds the response back, which is enough to trigger client timeouts
+	Delay time.Duration
+	// StatusCode answers with an error of this status, such as 429 or 500
+	StatusCode int
+	// Header is added to the response, for example Retry-After
+	Header http.Header
+	// Malformed answers 200 with a body that is not valid JSON
+	Malformed bool
+	// Times limits how many requests are affected; zero means every request
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+	return strings.HasPrefix(r.URL.Path, f.Path)
+}
+
+// InjectFault adds a fault. Faults are checked in the order they were added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// applyFault answers r according to the first matching fault and reports whether it did
+func (s *Server) applyFault(w http.ResponseWriter, r *http.Request) bool {
+	s.mu.Lock()
+	var fault *Fault
+	for i, f := range s.faults {
+		if !f.matches(r) {
+			continue
+		}
+		fault = f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		break
+	}
+	s.mu.Unlock()
+
+	if fault == nil {
+		return false
+	}
+
+	if fault.Delay > 0 {
+		select {
+		case <-time.After(fault.Delay):
+		case <-r.Context().Done():
+			return true
+		}
+	}
+
+	if fault.StatusCode == 0 && !fault.Malformed {
+		return false
+	}
+
+	for key, values := range fault.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	if fault.Malformed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "truncated`))
+		return true
+	}
+
+	writeError(w, fault.StatusCode, "injected fault")
+	return true
+}
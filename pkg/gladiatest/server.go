@@ -0,0 +1,632 @@
+package gladiatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/webhook"
+)
+
+const gladiaHeaderKey = "x-gladia-key"
+
+// Lifecycle scripts how a fake transcription job progresses after it is created
+type Lifecycle struct {
+	// QueuedFor is how long the job stays queued
+	QueuedFor time.Duration
+	// ProcessingFor is how long the job stays processing after leaving the queue
+	ProcessingFor time.Duration
+	// Fail ends the job in the error status instead of done
+	Fail bool
+	// ErrorCode is reported on failed jobs, defaulting to 500
+	ErrorCode int
+}
+
+// Server is an in-process fake of the Gladia pre-recorded API
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	apiKey     string
+	lifecycle  func(req *gladia.TranscriptionRequest) Lifecycle
+	result     func(req *gladia.TranscriptionRequest) gladia.TranscriptionResultData
+	jobs       map[string]*job
+	order      []string
+	files      map[string]*upload
+	faults     []*Fault
+	timers     []*time.Timer
+	nextID     int
+	callbacks  *http.Client
+	secret     []byte
+	deliveries []Delivery
+	now        func() time.Time
+}
+
+type job struct {
+	id        string
+	createdAt time.Time
+	request   gladia.TranscriptionRequest
+	lifecycle Lifecycle
+	result    gladia.TranscriptionResultData
+	file      *upload
+}
+
+type upload struct {
+	id       string
+	filename string
+	data     []byte
+}
+
+// Delivery records a callback posted by the server
+type Delivery struct {
+	URL        string
+	Event      gladia.CallbackEvent
+	StatusCode int
+	Err        error
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAPIKey makes the server reject requests that do not carry apiKey
+func WithAPIKey(apiKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithLifecycle sets the lifecycle used by every job
+func WithLifecycle(lifecycle Lifecycle) Option {
+	return func(s *Server) {
+		s.lifecycle = func(*gladia.TranscriptionRequest) Lifecycle { return lifecycle }
+	}
+}
+
+// WithLifecycleFunc picks the lifecycle of each job from its request
+func WithLifecycleFunc(fn func(req *gladia.TranscriptionRequest) Lifecycle) Option {
+	return func(s *Server) {
+		s.lifecycle = fn
+	}
+}
+
+// WithResult sets the canned result returned by every completed job
+func WithResult(result gladia.TranscriptionResultData) Option {
+	return func(s *Server) {
+		s.result = func(*gladia.TranscriptionRequest) gladia.TranscriptionResultData { return result }
+	}
+}
+
+// WithResultFunc builds the result of each job from its request
+func WithResultFunc(fn func(req *gladia.TranscriptionRequest) gladia.TranscriptionResultData) Option {
+	return func(s *Server) {
+		s.result = fn
+	}
+}
+
+// WithCallbackClient sets the HTTP client used to deliver callbacks
+func WithCallbackClient(client *http.Client) Option {
+	return func(s *Server) {
+		s.callbacks = client
+	}
+}
+
+// WithCallbackSecret signs callbacks with secret the way webhook.Verify checks them,
+// in the webhook.DefaultSignatureHeader and webhook.DefaultTimestampHeader headers
+func WithCallbackSecret(secret []byte) Option {
+	return func(s *Server) {
+		s.secret = secret
+	}
+}
+
+// WithClock sets the clock jobs are created and progress by, for example to list jobs
+// created on another day. Lifecycle timers and callbacks still run in real time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// NewServer starts a fake Gladia server. Callers must Close it when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		lifecycle: func(*gladia.TranscriptionRequest) Lifecycle { return Lifecycle{} },
+		result:    func(*gladia.TranscriptionRequest) gladia.TranscriptionResultData { return DefaultResult() },
+		jobs:      make(map[string]*job),
+		files:     make(map[string]*upload),
+		callbacks: &http.Client{Timeout: 5 * time.Second},
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v2/upload", s.handleUpload)
+	mux.HandleFunc("POST /v2/pre-recorded", s.handleCreate)
+	mux.HandleFunc("POST /v2/pre-recorded/{$}", s.handleCreate)
+	mux.HandleFunc("GET /v2/pre-recorded", s.handleList)
+	mux.HandleFunc("GET /v2/pre-recorded/{$}", s.handleList)
+	mux.HandleFunc("GET /v2/pre-recorded/{id}", s.handleGet)
+	mux.HandleFunc("DELETE /v2/pre-recorded/{id}", s.handleDelete)
+	mux.HandleFunc("GET /v2/pre-recorded/{id}/file", s.handleDownload)
+	mux.HandleFunc("GET /file/{id}", s.handleFile)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// Client returns a gladia.Client pointed at the server
+func (s *Server) Client(opts ...gladia.ClientOption) *gladia.Client {
+	opts = append([]gladia.ClientOption{gladia.WithBaseURL(s.URL + "/")}, opts...)
+	return gladia.NewClient(s.apiKey, opts...)
+}
+
+// Close stops pending callbacks and shuts the server down
+func (s *Server) Close() {
+	s.mu.Lock()
+	for _, timer := range s.timers {
+		timer.Stop()
+	}
+	s.timers = nil
+	s.mu.Unlock()
+
+	s.Server.Close()
+}
+
+// Job returns the current state of a job as the API would report it
+func (s *Server) Job(id string) (*gladia.GetTranscriptionStatus, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return s.snapshot(j, s.now()), true
+}
+
+// Deliveries returns the callbacks posted so far
+func (s *Server) Deliveries() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Delivery(nil), s.deliveries...)
+}
+
+// LoadResult reads a canned result from a JSON fixture. The file may hold either a
+// full CompletedTranscriptionResult or only its result object.
+func LoadResult(path string) (gladia.TranscriptionResultData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return gladia.TranscriptionResultData{}, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var envelope struct {
+		Result *gladia.TranscriptionResultData `json:"result"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return gladia.TranscriptionResultData{}, fmt.Errorf("failed to decode fixture: %w", err)
+	}
+	if envelope.Result != nil {
+		return *envelope.Result, nil
+	}
+
+	var result gladia.TranscriptionResultData
+	if err := json.Unmarshal(data, &result); err != nil {
+		return gladia.TranscriptionResultData{}, fmt.Errorf("failed to decode fixture: %w", err)
+	}
+	return result, nil
+}
+
+// DefaultResult is the result returned by completed jobs when none is configured
+func DefaultResult() gladia.TranscriptionResultData {
+	words := []gladia.Word{
+		{Word: "Hello", Start: 0.0, End: 0.4, Confidence: 0.98},
+		{Word: " from", Start: 0.4, End: 0.7, Confidence: 0.97},
+		{Word: " the", Start: 0.7, End: 0.8, Confidence: 0.99},
+		{Word: " test", Start: 0.8, End: 1.1, Confidence: 0.96},
+		{Word: " server.", Start: 1.1, End: 1.6, Confidence: 0.95},
+	}
+	text := "Hello from the test server."
+
+	return gladia.TranscriptionResultData{
+		Metadata: gladia.TranscriptionMetadata{
+			AudioDuration:            1.6,
+			NumberOfDistinctChannels: 1,
+			BillingTime:              1.6,
+			TranscriptionTime:        0.5,
+		},
+		Transcription: gladia.TranscriptionData{
+			FullTranscript: text,
+			Languages:      []string{"en"},
+			Utterances: []gladia.Utterance{{
+				Language:   "en",
+				Start:      0.0,
+				End:        1.6,
+				Confidence: 0.97,
+				Words:      words,
+				Text:       text,
+			}},
+		},
+	}
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.applyFault(w, r) {
+			return
+		}
+
+		if s.apiKey != "" && r.Header.Get(gladiaHeaderKey) != s.apiKey && !strings.HasPrefix(r.URL.Path, "/file/") {
+			writeError(w, http.StatusUnauthorized, "invalid or missing API key")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	file, header, err := r.FormFile("audio")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "missing audio form file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read audio")
+		return
+	}
+
+	s.mu.Lock()
+	u := &upload{id: s.newID(), filename: header.Filename, data: data}
+	s.files[u.id] = u
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, gladia.UploadResponse{
+		AudioURL: s.URL + "/file/" + u.id,
+		AudioMetadata: gladia.AudioMetadata{
+			ID:               u.id,
+			Filename:         u.filename,
+			Extension:        strings.TrimPrefix(filepath.Ext(u.filename), "."),
+			Size:             int64(len(data)),
+			NumberOfChannels: 1,
+		},
+	})
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req gladia.TranscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.AudioURL == "" {
+		writeError(w, http.StatusBadRequest, "audio_url is required")
+		return
+	}
+
+	s.mu.Lock()
+	j := &job{
+		id:        s.newID(),
+		createdAt: s.now(),
+		request:   req,
+		lifecycle: s.lifecycle(&req),
+		result:    s.result(&req),
+		file:      s.files[strings.TrimPrefix(req.AudioURL, s.URL+"/file/")],
+	}
+	s.jobs[j.id] = j
+	s.order = append(s.order, j.id)
+	if url := callbackURL(&req); url != "" {
+		s.timers = append(s.timers, time.AfterFunc(j.lifecycle.QueuedFor+j.lifecycle.ProcessingFor, func() {
+			s.deliver(url, j.id)
+		}))
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, gladia.TranscriptionResponse{
+		ID:        j.id,
+		ResultURL: s.URL + "/v2/pre-recorded/" + j.id,
+	})
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	statuses := query["status"]
+
+	var day string
+	if raw := query.Get("date"); raw != "" {
+		date, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid date filter")
+			return
+		}
+		day = date.Format(time.DateOnly)
+	}
+	before, ok := parseTime(query.Get("before_date"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid before_date filter")
+		return
+	}
+	after, ok := parseTime(query.Get("after_date"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid after_date filter")
+		return
+	}
+
+	var metadata gladia.CustomMetadata
+	if raw := query.Get("custom_metadata"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid custom_metadata filter")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	now := s.now()
+	var items []gladia.GetTranscriptionStatus
+	for i := len(s.order) - 1; i >= 0; i-- {
+		item := s.snapshot(s.jobs[s.order[i]], now)
+		if len(statuses) > 0 && !contains(statuses, item.Status) {
+			continue
+		}
+		if !item.CustomMetadata.Matches(metadata) {
+			continue
+		}
+		if day != "" && item.CreatedAt.UTC().Format(time.DateOnly) != day {
+			continue
+		}
+		if !before.IsZero() && !item.CreatedAt.Before(before) {
+			continue
+		}
+		if !after.IsZero() && !item.CreatedAt.After(after) {
+			continue
+		}
+		items = append(items, *item)
+	}
+	s.mu.Unlock()
+
+	page := gladia.TranscriptionList{
+		First:   s.pageURL(r, 0, limit),
+		Current: s.pageURL(r, offset, limit),
+		Items:   []gladia.GetTranscriptionStatus{},
+	}
+	if offset < len(items) {
+		page.Items = items[offset:min(offset+limit, len(items))]
+	}
+	if offset+limit < len(items) {
+		page.Next = s.pageURL(r, offset+limit, limit)
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	item, ok := s.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "transcription not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		writeError(w, http.StatusNotFound, "transcription not found")
+		return
+	}
+	delete(s.jobs, id)
+	for i, other := range s.order {
+		if other == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	j, ok := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "transcription not found")
+		return
+	}
+	if j.file == nil {
+		writeError(w, http.StatusNotFound, "audio file not available")
+		return
+	}
+	writeAudio(w, j.file)
+}
+
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	u, ok := s.files[r.PathValue("id")]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	writeAudio(w, u)
+}
+
+func (s *Server) deliver(url, id string) {
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	payload := s.snapshot(j, s.now())
+	s.mu.Unlock()
+
+	event := gladia.CallbackEvent{ID: id, Event: gladia.EventTranscriptionSuccess, Payload: payload}
+	if payload.Status == gladia.StatusError {
+		event.Event = gladia.EventTranscriptionError
+	}
+	delivery := Delivery{URL: url, Event: event}
+
+	resp, err := s.post(url, event)
+	if err == nil {
+		delivery.StatusCode = resp.StatusCode
+		resp.Body.Close()
+	}
+	delivery.Err = err
+
+	s.mu.Lock()
+	s.deliveries = append(s.deliveries, delivery)
+	s.mu.Unlock()
+}
+
+// post sends a callback event, signed when the server has a callback secret.
+// The signature is timestamped in real time since receivers check it against their clock.
+func (s *Server) post(url string, event gladia.CallbackEvent) (*http.Response, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.secret) > 0 {
+		now := time.Now()
+		req.Header.Set(webhook.DefaultSignatureHeader, webhook.Sign(s.secret, body, now))
+		req.Header.Set(webhook.DefaultTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	}
+	return s.callbacks.Do(req)
+}
+
+// snapshot reports a job as seen at now. The caller must hold s.mu.
+func (s *Server) snapshot(j *job, now time.Time) *gladia.GetTranscriptionStatus {
+	req := j.request
+	item := &gladia.GetTranscriptionStatus{
+		ID:             j.id,
+		RequestID:      "G-" + j.id,
+		Version:        2,
+		Status:         gladia.StatusQueued,
+		CreatedAt:      j.createdAt,
+		CustomMetadata: req.CustomMetadata,
+		Kind:           "pre-recorded",
+		RequestParams:  &req,
+		File: &gladia.GladiaFile{
+			ID:               j.id,
+			Source:           req.AudioURL,
+			AudioDuration:    j.result.Metadata.AudioDuration,
+			NumberOfChannels: max(j.result.Metadata.NumberOfDistinctChannels, 1),
+		},
+	}
+	if j.file != nil {
+		item.File.Filename = j.file.filename
+	}
+
+	elapsed := now.Sub(j.createdAt)
+	switch {
+	case elapsed < j.lifecycle.QueuedFor:
+		return item
+	case elapsed < j.lifecycle.QueuedFor+j.lifecycle.ProcessingFor:
+		item.Status = gladia.StatusProcessing
+		return item
+	}
+
+	completedAt := j.createdAt.Add(j.lifecycle.QueuedFor + j.lifecycle.ProcessingFor)
+	item.CompletedAt = &completedAt
+	if j.lifecycle.Fail {
+		code := j.lifecycle.ErrorCode
+		if code == 0 {
+			code = http.StatusInternalServerError
+		}
+		item.Status = gladia.StatusError
+		item.ErrorCode = &code
+		return item
+	}
+
+	result := j.result
+	item.Status = gladia.StatusDone
+	item.Result = &result
+	return item
+}
+
+// newID returns a unique identifier. The caller must hold s.mu.
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", s.nextID)
+}
+
+func (s *Server) pageURL(r *http.Request, offset, limit int) string {
+	query := r.URL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+	return s.URL + r.URL.Path + "?" + query.Encode()
+}
+
+func callbackURL(req *gladia.TranscriptionRequest) string {
+	if req.CallbackConfig != nil && req.CallbackConfig.URL != "" {
+		return req.CallbackConfig.URL
+	}
+	return req.CallbackURL
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTime parses an optional RFC 3339 filter, reporting false when it is malformed
+func parseTime(raw string) (time.Time, bool) {
+	if raw == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	return t, err == nil
+}
+
+func writeAudio(w http.ResponseWriter, u *upload) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", u.filename))
+	w.Write(u.data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, gladia.ErrorInfo{
+		StatusCode: status,
+		Exception:  http.StatusText(status),
+		Message:    message,
+	})
+}
@@ -0,0 +1,269 @@
+package gladiatest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/webhook"
+)
+
+// clock is a settable clock for WithClock
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+func statusCode(err error) int {
+	code, _ := gladiaerrors.StatusCode(err)
+	return code
+}
+
+// waitForDelivery returns the deliveries once the first one is recorded
+func waitForDelivery(server *Server) []Delivery {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(server.Deliveries()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return server.Deliveries()
+}
+
+func submit(t *testing.T, client *gladia.Client, req gladia.TranscriptionRequest) string {
+	t.Helper()
+	ctx := context.Background()
+	upload, err := client.UploadReader(ctx, "call.wav", strings.NewReader("audio"))
+	if err != nil {
+		t.Fatalf("UploadReader: %v", err)
+	}
+	req.AudioURL = upload.AudioURL
+	job, err := client.TranscribeWithRequest(ctx, &req)
+	if err != nil {
+		t.Fatalf("TranscribeWithRequest: %v", err)
+	}
+	return job.ID
+}
+
+func TestLifecycle(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := &clock{now: start}
+	server := NewServer(
+		WithClock(c.Now),
+		WithLifecycleFunc(func(req *gladia.TranscriptionRequest) Lifecycle {
+			return Lifecycle{
+				QueuedFor:     time.Second,
+				ProcessingFor: time.Second,
+				Fail:          req.CustomMetadata["fail"] == true,
+				ErrorCode:     422,
+			}
+		}),
+	)
+	defer server.Close()
+	client := server.Client()
+
+	done := submit(t, client, gladia.TranscriptionRequest{})
+	failed := submit(t, client, gladia.TranscriptionRequest{CustomMetadata: gladia.CustomMetadata{"fail": true}})
+
+	tests := []struct {
+		at     time.Duration
+		id     string
+		status string
+	}{
+		{at: 0, id: done, status: gladia.StatusQueued},
+		{at: 1500 * time.Millisecond, id: done, status: gladia.StatusProcessing},
+		{at: 2 * time.Second, id: done, status: gladia.StatusDone},
+		{at: 2 * time.Second, id: failed, status: gladia.StatusError},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s at %s", tt.id, tt.at), func(t *testing.T) {
+			c.Set(start.Add(tt.at))
+			status, err := client.GetTranscriptionStatus(context.Background(), tt.id)
+			if err != nil {
+				t.Fatalf("GetTranscriptionStatus: %v", err)
+			}
+			if status.Status != tt.status {
+				t.Errorf("status = %s, want %s", status.Status, tt.status)
+			}
+		})
+	}
+
+	result, err := client.GetTranscriptionResult(context.Background(), done)
+	if err != nil {
+		t.Fatalf("GetTranscriptionResult: %v", err)
+	}
+	if result.Result.Transcription.FullTranscript != DefaultResult().Transcription.FullTranscript {
+		t.Errorf("transcript = %q, want the default result", result.Result.Transcription.FullTranscript)
+	}
+	status, _ := server.Job(failed)
+	if status.ErrorCode == nil || *status.ErrorCode != 422 {
+		t.Errorf("error code = %v, want 422", status.ErrorCode)
+	}
+
+	if err := client.DeleteTranscription(context.Background(), done); err != nil {
+		t.Fatalf("DeleteTranscription: %v", err)
+	}
+	if _, err := client.GetTranscriptionStatus(context.Background(), done); statusCode(err) != http.StatusNotFound {
+		t.Errorf("GetTranscriptionStatus after delete = %v, want 404", err)
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	c := &clock{}
+	server := NewServer(WithClock(c.Now))
+	defer server.Close()
+	client := server.Client()
+
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	ids := map[string]string{}
+	for _, job := range []struct {
+		name    string
+		created time.Time
+		tenant  string
+	}{
+		{name: "yesterday", created: day.Add(-2 * time.Hour), tenant: "acme"},
+		{name: "morning", created: day.Add(9 * time.Hour), tenant: "acme"},
+		{name: "evening", created: day.Add(20 * time.Hour), tenant: "globex"},
+		{name: "tomorrow", created: day.Add(30 * time.Hour), tenant: "acme"},
+	} {
+		c.Set(job.created)
+		ids[submit(t, client, gladia.TranscriptionRequest{CustomMetadata: gladia.CustomMetadata{"tenant": job.tenant}})] = job.name
+	}
+	c.Set(day.Add(48 * time.Hour))
+
+	tests := []struct {
+		name string
+		opts gladia.ListOptions
+		want []string
+	}{
+		{name: "all newest first", want: []string{"tomorrow", "evening", "morning", "yesterday"}},
+		{name: "date", opts: gladia.ListOptions{Date: day}, want: []string{"evening", "morning"}},
+		{name: "before", opts: gladia.ListOptions{BeforeDate: day.Add(20 * time.Hour)}, want: []string{"morning", "yesterday"}},
+		{name: "after", opts: gladia.ListOptions{AfterDate: day.Add(9 * time.Hour)}, want: []string{"tomorrow", "evening"}},
+		{name: "metadata", opts: gladia.ListOptions{CustomMetadata: gladia.CustomMetadata{"tenant": "acme"}}, want: []string{"tomorrow", "morning", "yesterday"}},
+		{name: "status", opts: gladia.ListOptions{Status: []string{gladia.StatusError}}, want: []string{}},
+		{name: "paged", opts: gladia.ListOptions{Offset: 1, Limit: 2}, want: []string{"evening", "morning"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := client.ListTranscriptions(context.Background(), &tt.opts)
+			if err != nil {
+				t.Fatalf("ListTranscriptions: %v", err)
+			}
+			got := []string{}
+			for _, item := range page.Items {
+				got = append(got, ids[item.ID])
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	resp, err := http.Get(server.URL + "/v2/pre-recorded?before_date=yesterday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("malformed filter answered %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	server := NewServer(WithAPIKey("secret"))
+	defer server.Close()
+	ctx := context.Background()
+
+	if _, err := gladia.NewClient("wrong", gladia.WithBaseURL(server.URL+"/")).ListTranscriptions(ctx, &gladia.ListOptions{}); statusCode(err) != http.StatusUnauthorized {
+		t.Errorf("wrong key = %v, want 401", err)
+	}
+
+	client := server.Client()
+	server.InjectFault(Fault{Method: http.MethodGet, Path: "/v2/pre-recorded", StatusCode: http.StatusTooManyRequests, Times: 2})
+	for i := range 2 {
+		if _, err := client.ListTranscriptions(ctx, &gladia.ListOptions{}); statusCode(err) != http.StatusTooManyRequests {
+			t.Errorf("request %d = %v, want 429", i, err)
+		}
+	}
+	if _, err := client.ListTranscriptions(ctx, &gladia.ListOptions{}); err != nil {
+		t.Errorf("request after the fault = %v, want success", err)
+	}
+
+	server.InjectFault(Fault{Malformed: true, Times: 1})
+	if _, err := client.ListTranscriptions(ctx, &gladia.ListOptions{}); err == nil {
+		t.Error("malformed response decoded without error")
+	}
+
+	server.InjectFault(Fault{Delay: time.Second})
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.ListTranscriptions(ctx, &gladia.ListOptions{}); err == nil {
+		t.Error("delayed response arrived before the deadline")
+	}
+	server.ClearFaults()
+}
+
+func TestSignedCallback(t *testing.T) {
+	secret := []byte("callback-secret")
+	events := make(chan *gladia.CallbackEvent, 1)
+	receiver := httptest.NewServer(webhook.NewHandler(func(_ context.Context, event *gladia.CallbackEvent) error {
+		events <- event
+		return nil
+	}, webhook.WithSecret(secret)))
+	defer receiver.Close()
+
+	server := NewServer(WithCallbackSecret(secret))
+	defer server.Close()
+	id := submit(t, server.Client(), gladia.TranscriptionRequest{
+		Callback:       true,
+		CallbackConfig: &gladia.CallbackConfig{URL: receiver.URL},
+	})
+
+	select {
+	case event := <-events:
+		if event.ID != id || event.Event != gladia.EventTranscriptionSuccess {
+			t.Errorf("event = %+v, want success of %s", event, id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("signed callback was not accepted")
+	}
+
+	deliveries := waitForDelivery(server)
+	if len(deliveries) != 1 || deliveries[0].StatusCode != http.StatusOK {
+		t.Errorf("deliveries = %+v, want one accepted", deliveries)
+	}
+}
+
+func TestUnsignedCallbackIsRejected(t *testing.T) {
+	receiver := httptest.NewServer(webhook.NewHandler(func(context.Context, *gladia.CallbackEvent) error {
+		return nil
+	}, webhook.WithSecret([]byte("callback-secret"))))
+	defer receiver.Close()
+
+	server := NewServer()
+	defer server.Close()
+	submit(t, server.Client(), gladia.TranscriptionRequest{CallbackURL: receiver.URL})
+
+	deliveries := waitForDelivery(server)
+	if len(deliveries) != 1 || deliveries[0].StatusCode != http.StatusUnauthorized {
+		t.Errorf("deliveries = %+v, want one rejected with 401", deliveries)
+	}
+}
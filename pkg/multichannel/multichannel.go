@@ -0,0 +1,276 @@
+package multichannel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/audio"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Mode selects how the channels of a recording are transcribed
+type Mode int
+
+const (
+	// ModeAuto splits multi-channel WAV files locally and sends everything else as is
+	ModeAuto Mode = iota
+	// ModeNative submits the file as is and relies on Gladia to transcribe each channel
+	ModeNative
+	// ModeSplit uploads every channel as its own mono file
+	ModeSplit
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeNative:
+		return "native"
+	case ModeSplit:
+		return "split"
+	default:
+		return "auto"
+	}
+}
+
+// Transcriber is the part of gladia.Client used to transcribe channels
+type Transcriber interface {
+	UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error)
+	TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error)
+	WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error)
+}
+
+// Options configures a multi-channel transcription
+type Options struct {
+	Mode Mode
+	// Roles names each channel by index, e.g. {"agent", "customer"}
+	Roles []string
+	// Request is the template of every request; its audio URL is replaced
+	Request gladia.TranscriptionRequest
+	// WaitOptions are passed to WaitForTranscription
+	WaitOptions []gladia.WaitOption
+}
+
+// Role returns the role of a channel, or "Channel N" if it has none
+func (o *Options) Role(channel int) string {
+	if channel >= 0 && channel < len(o.Roles) && o.Roles[channel] != "" {
+		return o.Roles[channel]
+	}
+	return fmt.Sprintf("Channel %d", channel)
+}
+
+// Turn is an utterance attributed to the role of its channel
+type Turn struct {
+	gladia.Utterance
+	Role string `json:"role"`
+}
+
+// Conversation is a time-ordered transcription of every channel of a recording
+type Conversation struct {
+	Mode             Mode
+	Channels         int
+	TranscriptionIDs []string
+	Metadata         gladia.TranscriptionMetadata
+	Turns            []Turn
+}
+
+// Transcription returns the conversation as transcription data with time-ordered utterances
+func (c *Conversation) Transcription() gladia.TranscriptionData {
+	data := gladia.TranscriptionData{}
+	texts := make([]string, 0, len(c.Turns))
+	for _, turn := range c.Turns {
+		data.Utterances = append(data.Utterances, turn.Utterance)
+		texts = append(texts, strings.TrimSpace(turn.Text))
+	}
+	data.FullTranscript = strings.Join(texts, " ")
+	return data
+}
+
+// Choose resolves ModeAuto for a file: recordings with more than one channel are split
+// locally when their samples can be read, everything else is sent as is
+func Choose(mode Mode, file gladia.GladiaFile, splittable bool) Mode {
+	if mode != ModeAuto {
+		return mode
+	}
+	if file.NumberOfChannels > 1 && splittable {
+		return ModeSplit
+	}
+	return ModeNative
+}
+
+// TranscribeFile transcribes every channel of the audio file at path. Unless the mode is
+// ModeSplit the file is uploaded first, and ModeAuto decides from the channels Gladia
+// reports for the upload.
+func TranscribeFile(ctx context.Context, client Transcriber, path string, opts Options) (*Conversation, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Only PCM WAV samples can be split locally
+	header, err := audio.ReadWAVHeader(file)
+	splittable := err == nil && header.IsPCM()
+
+	mode := opts.Mode
+	var upload *gladia.UploadResponse
+	if mode != ModeSplit {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind file: %w", err)
+		}
+		if upload, err = client.UploadReader(ctx, filepath.Base(path), file); err != nil {
+			return nil, err
+		}
+		mode = Choose(mode, UploadedFile(upload), splittable)
+	}
+
+	if mode == ModeSplit {
+		if !splittable {
+			return nil, fmt.Errorf("cannot split %s into channels: only PCM WAV audio can be split", filepath.Base(path))
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		return transcribeSplit(ctx, client, file, header, name, opts)
+	}
+	return TranscribeURL(ctx, client, upload.AudioURL, opts)
+}
+
+// UploadedFile describes an uploaded file from the metadata Gladia returned for it
+func UploadedFile(upload *gladia.UploadResponse) gladia.GladiaFile {
+	return gladia.GladiaFile{
+		ID:               upload.AudioMetadata.ID,
+		Filename:         upload.AudioMetadata.Filename,
+		AudioDuration:    upload.AudioMetadata.AudioDuration,
+		NumberOfChannels: upload.AudioMetadata.NumberOfChannels,
+	}
+}
+
+// TranscribeURL transcribes audio already reachable by Gladia, relying on its native
+// multi-channel handling
+func TranscribeURL(ctx context.Context, client Transcriber, audioURL string, opts Options) (*Conversation, error) {
+	req := opts.Request
+	req.AudioURL = audioURL
+	job, err := client.TranscribeWithRequest(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := client.WaitForTranscription(ctx, job.ID, opts.WaitOptions...)
+	if err != nil {
+		return nil, err
+	}
+	return FromResult(result, opts), nil
+}
+
+// FromResult builds a conversation from a transcription of the whole recording. The
+// number of channels of the transcribed file decides whether utterance channels are kept;
+// single-channel audio has every utterance on channel zero.
+func FromResult(result *gladia.CompletedTranscriptionResult, opts Options) *Conversation {
+	channels := max(result.File.NumberOfChannels, 1)
+	conversation := &Conversation{
+		Mode:             ModeNative,
+		Channels:         channels,
+		TranscriptionIDs: []string{result.ID},
+		Metadata:         result.Result.Metadata,
+	}
+	for _, u := range result.Result.Transcription.Utterances {
+		if channels == 1 {
+			u.Channel = 0
+		}
+		conversation.Turns = append(conversation.Turns, Turn{Utterance: u, Role: opts.Role(u.Channel)})
+	}
+	conversation.sort()
+	return conversation
+}
+
+// Merge builds a conversation from one transcription per channel, in channel order
+func Merge(results []*gladia.CompletedTranscriptionResult, opts Options) *Conversation {
+	conversation := &Conversation{
+		Mode:     ModeSplit,
+		Channels: len(results),
+	}
+	for channel, result := range results {
+		conversation.TranscriptionIDs = append(conversation.TranscriptionIDs, result.ID)
+
+		metadata := result.Result.Metadata
+		conversation.Metadata.AudioDuration = max(conversation.Metadata.AudioDuration, metadata.AudioDuration)
+		conversation.Metadata.BillingTime += metadata.BillingTime
+		conversation.Metadata.TranscriptionTime += metadata.TranscriptionTime
+		conversation.Metadata.NumberOfDistinctChannels = len(results)
+
+		for _, u := range result.Result.Transcription.Utterances {
+			u.Channel = channel
+			conversation.Turns = append(conversation.Turns, Turn{Utterance: u, Role: opts.Role(channel)})
+		}
+	}
+	conversation.sort()
+	return conversation
+}
+
+func (c *Conversation) sort() {
+	sort.SliceStable(c.Turns, func(i, j int) bool {
+		if c.Turns[i].Start != c.Turns[j].Start {
+			return c.Turns[i].Start < c.Turns[j].Start
+		}
+		return c.Turns[i].Channel < c.Turns[j].Channel
+	})
+}
+
+func transcribeSplit(ctx context.Context, client Transcriber, r io.ReaderAt, header *audio.WAVHeader, name string, opts Options) (*Conversation, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The first failure cancels the other channels; their cancellation errors are not reported
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	results := make([]*gladia.CompletedTranscriptionResult, header.Channels)
+	var wg sync.WaitGroup
+	for channel := range header.Channels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := transcribeChannel(ctx, client, r, header, channel, name, opts)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("channel %d: %w", channel, err)
+				}
+				mu.Unlock()
+				cancel()
+				return
+			}
+			results[channel] = result
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return Merge(results, opts), nil
+}
+
+func transcribeChannel(ctx context.Context, client Transcriber, r io.ReaderAt, header *audio.WAVHeader, channel int, name string, opts Options) (*gladia.CompletedTranscriptionResult, error) {
+	samples, err := audio.Channel(r, header, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := client.UploadReader(ctx, fmt.Sprintf("%s-ch%d.wav", name, channel), samples)
+	if err != nil {
+		return nil, err
+	}
+
+	req := opts.Request
+	req.AudioURL = upload.AudioURL
+	job, err := client.TranscribeWithRequest(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return client.WaitForTranscription(ctx, job.ID, opts.WaitOptions...)
+}
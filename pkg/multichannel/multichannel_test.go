@@ -0,0 +1,186 @@
+package multichannel
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/audio"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// writeStereo writes a short stereo WAV whose left samples are 1000 and right samples 2000
+func writeStereo(t *testing.T) string {
+	t.Helper()
+	const frames = 100
+	header := audio.PCMHeader(audio.PCMFormat{SampleRate: 100, Channels: 2, BitsPerSample: 16}, frames*4)
+	data := audio.EncodeWAVHeader(header, frames*4)
+	for range frames {
+		data = binary.LittleEndian.AppendUint16(data, 1000)
+		data = binary.LittleEndian.AppendUint16(data, 2000)
+	}
+	path := filepath.Join(t.TempDir(), "call.wav")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fakeTranscriber answers stereo uploads with a native multi-channel result and mono
+// uploads with one utterance naming the channel its samples came from
+type fakeTranscriber struct {
+	// reported is the number of channels reported in the metadata of stereo uploads
+	reported int
+	failing  int
+	err      error
+
+	mu      sync.Mutex
+	uploads []string
+}
+
+func (f *fakeTranscriber) UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	header, err := audio.ReadWAVHeader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	url, channels := "stereo", f.reported
+	if header.Channels == 1 {
+		sample := binary.LittleEndian.Uint16(data[header.DataOffset:])
+		url, channels = fmt.Sprintf("mono-%d", sample/1000-1), 1
+	}
+	f.mu.Lock()
+	f.uploads = append(f.uploads, url)
+	f.mu.Unlock()
+	return &gladia.UploadResponse{AudioURL: url, AudioMetadata: gladia.AudioMetadata{Filename: filename, NumberOfChannels: channels}}, nil
+}
+
+func (f *fakeTranscriber) TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error) {
+	return &gladia.TranscriptionResponse{ID: req.AudioURL}, nil
+}
+
+func (f *fakeTranscriber) WaitForTranscription(ctx context.Context, id string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error) {
+	result := &gladia.CompletedTranscriptionResult{ID: id, Status: gladia.StatusDone}
+	if id == "stereo" {
+		result.File = gladia.GladiaFile{NumberOfChannels: f.reported}
+		result.Result.Transcription.Utterances = []gladia.Utterance{
+			{Channel: 1, Start: 0.5, End: 1, Text: "native right"},
+			{Channel: 0, Start: 0, End: 0.5, Text: "native left"},
+		}
+		return result, nil
+	}
+
+	var channel int
+	fmt.Sscanf(id, "mono-%d", &channel)
+	if f.err != nil {
+		if channel == f.failing {
+			time.Sleep(10 * time.Millisecond)
+			return nil, f.err
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	// The right channel speaks first
+	start := 0.4 - 0.2*float64(channel)
+	result.Result.Transcription.Utterances = []gladia.Utterance{
+		{Start: start, End: start + 0.1, Text: fmt.Sprintf("channel %d", channel)},
+	}
+	return result, nil
+}
+
+func TestTranscribeFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		reported int
+		wantMode Mode
+		want     []Turn
+	}{
+		{
+			name:     "auto splits what Gladia reports as stereo",
+			reported: 2,
+			wantMode: ModeSplit,
+			want: []Turn{
+				{Utterance: gladia.Utterance{Channel: 1, Text: "channel 1"}, Role: "customer"},
+				{Utterance: gladia.Utterance{Channel: 0, Text: "channel 0"}, Role: "agent"},
+			},
+		},
+		{
+			name:     "auto sends what Gladia reports as mono",
+			reported: 1,
+			wantMode: ModeNative,
+			want: []Turn{
+				{Utterance: gladia.Utterance{Channel: 0, Text: "native left"}, Role: "agent"},
+				{Utterance: gladia.Utterance{Channel: 0, Text: "native right"}, Role: "agent"},
+			},
+		},
+		{
+			name:     "native",
+			mode:     ModeNative,
+			reported: 2,
+			wantMode: ModeNative,
+			want: []Turn{
+				{Utterance: gladia.Utterance{Channel: 0, Text: "native left"}, Role: "agent"},
+				{Utterance: gladia.Utterance{Channel: 1, Text: "native right"}, Role: "customer"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeTranscriber{reported: tt.reported}
+			opts := Options{Mode: tt.mode, Roles: []string{"agent", "customer"}}
+			conversation, err := TranscribeFile(context.Background(), client, writeStereo(t), opts)
+			if err != nil {
+				t.Fatalf("TranscribeFile: %v", err)
+			}
+			if conversation.Mode != tt.wantMode {
+				t.Errorf("mode = %v, want %v", conversation.Mode, tt.wantMode)
+			}
+			if len(conversation.Turns) != len(tt.want) {
+				t.Fatalf("got %d turns, want %d", len(conversation.Turns), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := conversation.Turns[i]
+				if got.Channel != want.Channel || got.Text != want.Text || got.Role != want.Role {
+					t.Errorf("turn %d = %d %q %q, want %d %q %q", i, got.Channel, got.Role, got.Text, want.Channel, want.Role, want.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscribeFileReportsTheFailingChannel(t *testing.T) {
+	failure := errors.New("channel rejected")
+	client := &fakeTranscriber{reported: 2, failing: 1, err: failure}
+
+	_, err := TranscribeFile(context.Background(), client, writeStereo(t), Options{Mode: ModeSplit})
+	if !errors.Is(err, failure) {
+		t.Errorf("got %v, want the failing channel's error rather than a sibling's cancellation", err)
+	}
+	if len(client.uploads) != 2 {
+		t.Errorf("uploads = %v, want only the two channels in split mode", client.uploads)
+	}
+}
+
+func TestSplitNeedsPCM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "call.mp3")
+	if err := os.WriteFile(path, []byte("ID3 not a wav"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TranscribeFile(context.Background(), &fakeTranscriber{}, path, Options{Mode: ModeSplit}); err == nil {
+		t.Error("expected an error splitting audio that is not PCM WAV")
+	}
+}
@@ -0,0 +1,446 @@
+package otelgladia
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/fulviodenza/go-gladia-client/pkg/otelgladia"
+
+// jobRetention bounds how long a job is tracked, so jobs never seen finishing do not
+// accumulate in long-running processes
+const jobRetention = 24 * time.Hour
+
+// Operation names used for spans and the operation attribute
+const (
+	OperationUpload = "upload"
+	OperationSubmit = "submit"
+	OperationPoll   = "poll"
+	OperationGet    = "get"
+	OperationDelete = "delete"
+	OperationList   = "list"
+)
+
+// Attribute keys set on spans and metrics
+const (
+	OperationKey       = attribute.Key("gladia.operation")
+	TranscriptionIDKey = attribute.Key("gladia.transcription.id")
+	StatusKey          = attribute.Key("gladia.transcription.status")
+	AudioDurationKey   = attribute.Key("gladia.audio.duration")
+	UploadSizeKey      = attribute.Key("gladia.upload.size")
+	ErrorTypeKey       = attribute.Key("error.type")
+)
+
+// Client wraps a gladia.Client and records a span and metrics for every API call
+type Client struct {
+	*gladia.Client
+
+	tracer trace.Tracer
+
+	requestDuration metric.Float64Histogram
+	errorCount      metric.Int64Counter
+	uploadBytes     metric.Int64Counter
+	turnaround      metric.Float64Histogram
+	billedSeconds   metric.Float64Counter
+
+	jobs jobTracker
+}
+
+// jobTracker remembers when jobs were submitted and which were billed, forgetting them
+// after jobRetention
+type jobTracker struct {
+	mu        sync.Mutex
+	submitted map[string]time.Time
+	billed    map[string]time.Time
+	pruned    time.Time
+}
+
+// submit records the submission time of a job
+func (t *jobTracker) submit(transcriptionID string, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(at)
+	if t.submitted == nil {
+		t.submitted = make(map[string]time.Time)
+	}
+	t.submitted[transcriptionID] = at
+}
+
+// finish forgets the submission of a finished job, returning its submission time if it
+// was known, and reports whether the job is finished for the first time
+func (t *jobTracker) finish(transcriptionID string, now time.Time) (time.Time, bool, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune(now)
+	submittedAt, submitted := t.submitted[transcriptionID]
+	delete(t.submitted, transcriptionID)
+
+	if _, ok := t.billed[transcriptionID]; ok {
+		return submittedAt, submitted, false
+	}
+	if t.billed == nil {
+		t.billed = make(map[string]time.Time)
+	}
+	t.billed[transcriptionID] = now
+	return submittedAt, submitted, true
+}
+
+// forget drops a job that was deleted or will not be seen finishing
+func (t *jobTracker) forget(transcriptionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.submitted, transcriptionID)
+	delete(t.billed, transcriptionID)
+}
+
+// prune drops jobs older than jobRetention, at most once a minute. The caller must hold t.mu.
+func (t *jobTracker) prune(now time.Time) {
+	if now.Sub(t.pruned) < time.Minute {
+		return
+	}
+	t.pruned = now
+	for id, at := range t.submitted {
+		if now.Sub(at) > jobRetention {
+			delete(t.submitted, id)
+		}
+	}
+	for id, at := range t.billed {
+		if now.Sub(at) > jobRetention {
+			delete(t.billed, id)
+		}
+	}
+}
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+}
+
+// Option configures the instrumentation
+type Option func(*config)
+
+// WithTracerProvider sets the tracer provider, defaulting to the global one
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the meter provider, defaulting to the global one
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// NewClient wraps client with OpenTelemetry tracing and metrics
+func NewClient(client *gladia.Client, opts ...Option) (*Client, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(instrumentationName)
+	c := &Client{
+		Client: client,
+		tracer: cfg.tracerProvider.Tracer(instrumentationName),
+	}
+
+	var err error
+	if c.requestDuration, err = meter.Float64Histogram("gladia.client.request.duration",
+		metric.WithDescription("Duration of Gladia API calls"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if c.errorCount, err = meter.Int64Counter("gladia.client.errors",
+		metric.WithDescription("Number of failed Gladia API calls"),
+		metric.WithUnit("{error}")); err != nil {
+		return nil, err
+	}
+	if c.uploadBytes, err = meter.Int64Counter("gladia.client.upload.size",
+		metric.WithDescription("Bytes of audio uploaded to Gladia"),
+		metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	if c.turnaround, err = meter.Float64Histogram("gladia.transcription.turnaround",
+		metric.WithDescription("Time from submitting a transcription to observing it done"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if c.billedSeconds, err = meter.Float64Counter("gladia.transcription.billed",
+		metric.WithDescription("Billed audio time reported by Gladia"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// UploadFile uploads an audio file, recording its size and duration
+func (c *Client) UploadFile(ctx context.Context, filePath string) (*gladia.UploadResponse, error) {
+	ctx, span := c.start(ctx, OperationUpload)
+	begin := time.Now()
+
+	var size int64
+	if info, err := os.Stat(filePath); err == nil {
+		size = info.Size()
+		span.SetAttributes(UploadSizeKey.Int64(size))
+	}
+
+	resp, err := c.Client.UploadFile(ctx, filePath)
+	if err == nil {
+		span.SetAttributes(AudioDurationKey.Float64(resp.AudioMetadata.AudioDuration))
+		c.uploadBytes.Add(ctx, size)
+	}
+	c.end(ctx, span, OperationUpload, begin, err)
+
+	return resp, err
+}
+
+// UploadReader uploads audio read from r, recording the number of bytes sent
+func (c *Client) UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error) {
+	ctx, span := c.start(ctx, OperationUpload)
+	begin := time.Now()
+
+	// Readers of a known size are passed through untouched so the upload keeps its
+	// Content-Length; others are counted as they are read
+	size, known := readerSize(r)
+	var counter *countingReader
+	if !known {
+		counter = &countingReader{r: r}
+		r = counter
+	}
+
+	resp, err := c.Client.UploadReader(ctx, filename, r)
+	if counter != nil {
+		size = counter.n
+	}
+	span.SetAttributes(UploadSizeKey.Int64(size))
+	if err == nil {
+		span.SetAttributes(AudioDurationKey.Float64(resp.AudioMetadata.AudioDuration))
+		c.uploadBytes.Add(ctx, size)
+	}
+	c.end(ctx, span, OperationUpload, begin, err)
+
+	return resp, err
+}
+
+// readerSize returns the bytes left in a reader that has a Len method or is a regular
+// file, the readers gladia.Client uploads with a Content-Length
+func readerSize(r io.Reader) (int64, bool) {
+	switch r := r.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+		return info.Size() - offset, true
+	}
+	return 0, false
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Transcribe submits a transcription and remembers when it was submitted
+func (c *Client) Transcribe(ctx context.Context, audioURL string) (*gladia.TranscriptionResponse, error) {
+	return c.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{AudioURL: audioURL})
+}
+
+// TranscribeWithRequest submits a configured transcription and remembers when it was submitted
+func (c *Client) TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error) {
+	ctx, span := c.start(ctx, OperationSubmit)
+	begin := time.Now()
+
+	resp, err := c.Client.TranscribeWithRequest(ctx, req)
+	if err == nil {
+		span.SetAttributes(TranscriptionIDKey.String(resp.ID))
+		c.jobs.submit(resp.ID, begin)
+	}
+	c.end(ctx, span, OperationSubmit, begin, err)
+
+	return resp, err
+}
+
+// GetTranscriptionStatus polls the status of a transcription
+func (c *Client) GetTranscriptionStatus(ctx context.Context, transcriptionID string) (*gladia.GetTranscriptionStatus, error) {
+	ctx, span := c.start(ctx, OperationPoll, TranscriptionIDKey.String(transcriptionID))
+	begin := time.Now()
+
+	status, err := c.Client.GetTranscriptionStatus(ctx, transcriptionID)
+	if err == nil {
+		span.SetAttributes(StatusKey.String(status.Status))
+		if status.File != nil {
+			span.SetAttributes(AudioDurationKey.Float64(status.File.AudioDuration))
+		}
+		var metadata *gladia.TranscriptionMetadata
+		if status.Result != nil {
+			metadata = &status.Result.Metadata
+		}
+		c.observeStatus(ctx, transcriptionID, status.Status, metadata)
+	} else if isNotFound(err) {
+		c.jobs.forget(transcriptionID)
+	}
+	c.end(ctx, span, OperationPoll, begin, err)
+
+	return status, err
+}
+
+// GetTranscriptionResult retrieves the result of a transcription
+func (c *Client) GetTranscriptionResult(ctx context.Context, transcriptionID string) (*gladia.CompletedTranscriptionResult, error) {
+	ctx, span := c.start(ctx, OperationGet, TranscriptionIDKey.String(transcriptionID))
+	begin := time.Now()
+
+	result, err := c.Client.GetTranscriptionResult(ctx, transcriptionID)
+	if err == nil {
+		span.SetAttributes(
+			StatusKey.String(result.Status),
+			AudioDurationKey.Float64(result.File.AudioDuration),
+		)
+		c.observeStatus(ctx, transcriptionID, result.Status, &result.Result.Metadata)
+	} else if isNotFound(err) {
+		c.jobs.forget(transcriptionID)
+	}
+	c.end(ctx, span, OperationGet, begin, err)
+
+	return result, err
+}
+
+// WaitForTranscription waits for a transcription to finish through the instrumented
+// calls, so every poll and fetch gets a span. A job whose wait fails is no longer tracked.
+func (c *Client) WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error) {
+	result, err := c.Client.WaitForTranscriptionUsing(ctx, c, transcriptionID, opts...)
+	if err != nil {
+		c.jobs.forget(transcriptionID)
+	}
+	return result, err
+}
+
+// DeleteTranscription deletes a transcription
+func (c *Client) DeleteTranscription(ctx context.Context, transcriptionID string) error {
+	ctx, span := c.start(ctx, OperationDelete, TranscriptionIDKey.String(transcriptionID))
+	begin := time.Now()
+
+	err := c.Client.DeleteTranscription(ctx, transcriptionID)
+	// Whether or not the delete succeeded, the job is not expected to be seen finishing
+	c.jobs.forget(transcriptionID)
+	c.end(ctx, span, OperationDelete, begin, err)
+
+	return err
+}
+
+// ListTranscriptions lists transcriptions
+func (c *Client) ListTranscriptions(ctx context.Context, opts *gladia.ListOptions) (*gladia.TranscriptionList, error) {
+	ctx, span := c.start(ctx, OperationList)
+	begin := time.Now()
+
+	list, err := c.Client.ListTranscriptions(ctx, opts)
+	c.end(ctx, span, OperationList, begin, err)
+
+	return list, err
+}
+
+// observeStatus records billing the first time a job is seen finished, and turnaround for
+// jobs submitted through this client. Fetching a finished job again is not billed twice.
+func (c *Client) observeStatus(ctx context.Context, transcriptionID, status string, metadata *gladia.TranscriptionMetadata) {
+	if status != gladia.StatusDone && status != gladia.StatusError {
+		return
+	}
+
+	now := time.Now()
+	submittedAt, submitted, first := c.jobs.finish(transcriptionID, now)
+	attrs := metric.WithAttributes(StatusKey.String(status))
+	if submitted {
+		c.turnaround.Record(ctx, now.Sub(submittedAt).Seconds(), attrs)
+	}
+	if first && metadata != nil && metadata.BillingTime > 0 {
+		c.billedSeconds.Add(ctx, metadata.BillingTime, attrs)
+	}
+}
+
+func isNotFound(err error) bool {
+	code, ok := gladiaerrors.StatusCode(err)
+	return ok && code == http.StatusNotFound
+}
+
+func (c *Client) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, OperationKey.String(operation))
+	return c.tracer.Start(ctx, "gladia."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+func (c *Client) end(ctx context.Context, span trace.Span, operation string, begin time.Time, err error) {
+	defer span.End()
+
+	attrs := []attribute.KeyValue{OperationKey.String(operation)}
+	if err != nil {
+		kind := ErrorKind(err)
+		attrs = append(attrs, ErrorTypeKey.String(kind))
+		span.SetAttributes(ErrorTypeKey.String(kind))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		c.errorCount.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+
+	c.requestDuration.Record(ctx, time.Since(begin).Seconds(), metric.WithAttributes(attrs...))
+}
+
+// ErrorKind classifies err into a low-cardinality value suitable for the error.type attribute.
+// API errors are reported by their HTTP status code.
+func ErrorKind(err error) string {
+	if code, ok := gladiaerrors.StatusCode(err); ok {
+		return strconv.Itoa(code)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return "timeout"
+	}
+
+	return "other"
+}
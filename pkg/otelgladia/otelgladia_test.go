@@ -0,0 +1,437 @@
+package otelgladia
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladiatest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const unknownID = "00000000-0000-4000-8000-999999999999"
+
+type harness struct {
+	server *gladiatest.Server
+	client *Client
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+}
+
+func newHarness(t *testing.T, opts ...gladiatest.Option) *harness {
+	t.Helper()
+
+	server := gladiatest.NewServer(opts...)
+	t.Cleanup(server.Close)
+
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	client, err := NewClient(server.Client(),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return &harness{server: server, client: client, spans: spans, reader: reader}
+}
+
+func (h *harness) submit(t *testing.T) string {
+	t.Helper()
+	job, err := h.client.Transcribe(context.Background(), "https://example.com/audio.wav")
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	return job.ID
+}
+
+func (h *harness) metrics(t *testing.T) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := h.reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func writeAudio(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audio.wav")
+	if err := os.WriteFile(path, []byte("RIFF0000WAVE"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSpans(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(t *testing.T, h *harness) (id string, err error)
+		span      string
+		attrs     []attribute.KeyValue
+		errorType string
+	}{
+		{
+			name: "upload",
+			call: func(t *testing.T, h *harness) (string, error) {
+				_, err := h.client.UploadFile(context.Background(), writeAudio(t))
+				return "", err
+			},
+			span:  "gladia.upload",
+			attrs: []attribute.KeyValue{OperationKey.String(OperationUpload), UploadSizeKey.Int64(12)},
+		},
+		{
+			name: "upload reader",
+			call: func(t *testing.T, h *harness) (string, error) {
+				_, err := h.client.UploadReader(context.Background(), "audio.wav", strings.NewReader("RIFF0000WAVE"))
+				return "", err
+			},
+			span:  "gladia.upload",
+			attrs: []attribute.KeyValue{OperationKey.String(OperationUpload), UploadSizeKey.Int64(12)},
+		},
+		{
+			name: "upload unsized reader",
+			call: func(t *testing.T, h *harness) (string, error) {
+				r := io.MultiReader(strings.NewReader("RIFF0000"), strings.NewReader("WAVE"))
+				_, err := h.client.UploadReader(context.Background(), "audio.wav", r)
+				return "", err
+			},
+			span:  "gladia.upload",
+			attrs: []attribute.KeyValue{OperationKey.String(OperationUpload), UploadSizeKey.Int64(12)},
+		},
+		{
+			name: "submit",
+			call: func(t *testing.T, h *harness) (string, error) {
+				job, err := h.client.Transcribe(context.Background(), "https://example.com/audio.wav")
+				if err != nil {
+					return "", err
+				}
+				return job.ID, nil
+			},
+			span:  "gladia.submit",
+			attrs: []attribute.KeyValue{OperationKey.String(OperationSubmit)},
+		},
+		{
+			name: "poll",
+			call: func(t *testing.T, h *harness) (string, error) {
+				id := h.submit(t)
+				_, err := h.client.GetTranscriptionStatus(context.Background(), id)
+				return id, err
+			},
+			span:  "gladia.poll",
+			attrs: []attribute.KeyValue{OperationKey.String(OperationPoll), StatusKey.String(gladia.StatusDone)},
+		},
+		{
+			name: "get",
+			call: func(t *testing.T, h *harness) (string, error) {
+				id := h.submit(t)
+				_, err := h.client.GetTranscriptionResult(context.Background(), id)
+				return id, err
+			},
+			span: "gladia.get",
+			attrs: []attribute.KeyValue{
+				OperationKey.String(OperationGet),
+				StatusKey.String(gladia.StatusDone),
+				AudioDurationKey.Float64(1.6),
+			},
+		},
+		{
+			name: "delete",
+			call: func(t *testing.T, h *harness) (string, error) {
+				id := h.submit(t)
+				return id, h.client.DeleteTranscription(context.Background(), id)
+			},
+			span:  "gladia.delete",
+			attrs: []attribute.KeyValue{OperationKey.String(OperationDelete)},
+		},
+		{
+			name: "poll unknown",
+			call: func(t *testing.T, h *harness) (string, error) {
+				_, err := h.client.GetTranscriptionStatus(context.Background(), unknownID)
+				return unknownID, err
+			},
+			span:      "gladia.poll",
+			attrs:     []attribute.KeyValue{OperationKey.String(OperationPoll)},
+			errorType: "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			id, err := tt.call(t, h)
+			if tt.errorType == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.errorType != "" && err == nil {
+				t.Fatal("expected an error")
+			}
+
+			spans := h.spans.GetSpans()
+			span := spans[len(spans)-1]
+			if span.Name != tt.span {
+				t.Fatalf("span name = %q, want %q", span.Name, tt.span)
+			}
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, kv := range span.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			want := tt.attrs
+			if id != "" {
+				want = append(want, TranscriptionIDKey.String(id))
+			}
+			if tt.errorType != "" {
+				want = append(want, ErrorTypeKey.String(tt.errorType))
+			}
+			for _, kv := range want {
+				if got, ok := attrs[kv.Key]; !ok || got != kv.Value {
+					t.Errorf("attribute %s = %v, want %v", kv.Key, got.Emit(), kv.Value.Emit())
+				}
+			}
+
+			wantStatus := codes.Unset
+			if tt.errorType != "" {
+				wantStatus = codes.Error
+			}
+			if span.Status.Code != wantStatus {
+				t.Errorf("span status = %v, want %v", span.Status.Code, wantStatus)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	if _, err := h.client.UploadFile(ctx, writeAudio(t)); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	id := h.submit(t)
+	for range 2 {
+		// Fetching a finished job twice must not bill it twice
+		if _, err := h.client.GetTranscriptionResult(ctx, id); err != nil {
+			t.Fatalf("GetTranscriptionResult: %v", err)
+		}
+	}
+	if _, err := h.client.GetTranscriptionStatus(ctx, unknownID); err == nil {
+		t.Fatal("expected an error polling an unknown job")
+	}
+
+	metrics := h.metrics(t)
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T, data metricdata.Aggregation)
+	}{
+		{
+			name: "gladia.client.request.duration",
+			check: func(t *testing.T, data metricdata.Aggregation) {
+				counts := make(map[string]uint64)
+				for _, point := range data.(metricdata.Histogram[float64]).DataPoints {
+					operation, _ := point.Attributes.Value(OperationKey)
+					counts[operation.AsString()] += point.Count
+				}
+				want := map[string]uint64{OperationUpload: 1, OperationSubmit: 1, OperationGet: 2, OperationPoll: 1}
+				for operation, count := range want {
+					if counts[operation] != count {
+						t.Errorf("%s calls = %d, want %d", operation, counts[operation], count)
+					}
+				}
+			},
+		},
+		{
+			name: "gladia.client.errors",
+			check: func(t *testing.T, data metricdata.Aggregation) {
+				points := data.(metricdata.Sum[int64]).DataPoints
+				if len(points) != 1 {
+					t.Fatalf("got %d data points, want 1", len(points))
+				}
+				kind, _ := points[0].Attributes.Value(ErrorTypeKey)
+				operation, _ := points[0].Attributes.Value(OperationKey)
+				if points[0].Value != 1 || kind.AsString() != "404" || operation.AsString() != OperationPoll {
+					t.Errorf("got %d errors of kind %q on %q, want 1 of kind 404 on poll",
+						points[0].Value, kind.AsString(), operation.AsString())
+				}
+			},
+		},
+		{
+			name: "gladia.client.upload.size",
+			check: func(t *testing.T, data metricdata.Aggregation) {
+				points := data.(metricdata.Sum[int64]).DataPoints
+				if len(points) != 1 || points[0].Value != 12 {
+					t.Errorf("got %+v, want 12 bytes", points)
+				}
+			},
+		},
+		{
+			name: "gladia.transcription.turnaround",
+			check: func(t *testing.T, data metricdata.Aggregation) {
+				points := data.(metricdata.Histogram[float64]).DataPoints
+				if len(points) != 1 || points[0].Count != 1 {
+					t.Errorf("got %+v, want one observation", points)
+				}
+			},
+		},
+		{
+			name: "gladia.transcription.billed",
+			check: func(t *testing.T, data metricdata.Aggregation) {
+				points := data.(metricdata.Sum[float64]).DataPoints
+				if len(points) != 1 || points[0].Value != 1.6 {
+					t.Errorf("got %+v, want 1.6 seconds", points)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, ok := metrics[tt.name]
+			if !ok {
+				t.Fatalf("instrument %s was not recorded", tt.name)
+			}
+			tt.check(t, data)
+		})
+	}
+}
+
+func TestBillingOfJobsSubmittedElsewhere(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+
+	job, err := h.server.Client().Transcribe(ctx, "https://example.com/audio.wav")
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if _, err := h.client.GetTranscriptionStatus(ctx, job.ID); err != nil {
+		t.Fatalf("GetTranscriptionStatus: %v", err)
+	}
+
+	metrics := h.metrics(t)
+	billed, ok := metrics["gladia.transcription.billed"].(metricdata.Sum[float64])
+	if !ok || len(billed.DataPoints) != 1 || billed.DataPoints[0].Value != 1.6 {
+		t.Errorf("billed = %+v, want 1.6 seconds", billed.DataPoints)
+	}
+	if _, ok := metrics["gladia.transcription.turnaround"]; ok {
+		t.Error("turnaround recorded for a job submitted elsewhere")
+	}
+}
+
+func TestJobTracking(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(h *harness, id string)
+	}{
+		{
+			name: "deleted",
+			finish: func(h *harness, id string) {
+				h.client.DeleteTranscription(context.Background(), id)
+			},
+		},
+		{
+			name: "wait failed",
+			finish: func(h *harness, id string) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				h.client.WaitForTranscription(ctx, id)
+			},
+		},
+		{
+			name: "finished",
+			finish: func(h *harness, id string) {
+				h.client.GetTranscriptionStatus(context.Background(), id)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			tt.finish(h, h.submit(t))
+
+			h.client.jobs.mu.Lock()
+			defer h.client.jobs.mu.Unlock()
+			if len(h.client.jobs.submitted) != 0 {
+				t.Errorf("%d jobs still tracked", len(h.client.jobs.submitted))
+			}
+		})
+	}
+}
+
+func TestWaitSpans(t *testing.T) {
+	tests := []struct {
+		name      string
+		lifecycle gladiatest.Lifecycle
+		timeout   time.Duration
+		opts      []gladia.WaitOption
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "done",
+			lifecycle: gladiatest.Lifecycle{ProcessingFor: 30 * time.Millisecond},
+			want:      []string{"gladia.poll", "gladia.get"},
+		},
+		{
+			name:      "failed",
+			lifecycle: gladiatest.Lifecycle{Fail: true},
+			want:      []string{"gladia.poll"},
+			wantErr:   true,
+		},
+		{
+			name:      "abandoned",
+			lifecycle: gladiatest.Lifecycle{QueuedFor: time.Hour},
+			timeout:   30 * time.Millisecond,
+			opts:      []gladia.WaitOption{gladia.WithCancelRemoteOnContextDone()},
+			want:      []string{"gladia.poll", "gladia.delete"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t, gladiatest.WithLifecycle(tt.lifecycle))
+			id := h.submit(t)
+			h.spans.Reset()
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			opts := append([]gladia.WaitOption{gladia.WithPollInterval(5 * time.Millisecond)}, tt.opts...)
+			_, err := h.client.WaitForTranscription(ctx, id, opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForTranscription error = %v, want error %v", err, tt.wantErr)
+			}
+
+			seen := make(map[string]int)
+			for _, span := range h.spans.GetSpans() {
+				seen[span.Name]++
+			}
+			for _, name := range tt.want {
+				if seen[name] == 0 {
+					t.Errorf("no %s span recorded while waiting, got %v", name, seen)
+				}
+			}
+		})
+	}
+}
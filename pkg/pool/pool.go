@@ -0,0 +1,576 @@
+package pool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Strategy selects the member serving a new request
+type Strategy int
+
+const (
+	// RoundRobin cycles through members in order
+	RoundRobin Strategy = iota
+	// LeastInFlight picks the member with the fewest calls in progress
+	LeastInFlight
+	// Weighted spreads requests in proportion to member weights
+	Weighted
+)
+
+// ErrEmpty is returned when the pool has no members
+var ErrEmpty = errors.New("pool has no clients")
+
+// defaultBindingTTL is how long an unused binding is kept
+const defaultBindingTTL = 24 * time.Hour
+
+// member is a client of the pool. Its client is swapped when its key is rotated.
+type member struct {
+	name   string
+	weight int
+
+	// current is the smooth weighted round-robin credit; it and weight are guarded by the pool
+	current int
+
+	mu        sync.Mutex
+	client    *gladia.Client
+	inFlight  int
+	coolUntil time.Time
+}
+
+func (m *member) get() *gladia.Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.client
+}
+
+// Pool spreads requests over several Gladia clients, usually one per API key or account.
+// Requests creating a transcription fail over to another member when a key is rejected
+// (401), out of credit (402) or rate limited (429). Transcriptions and uploads stay bound
+// to the member that created them, so later calls about them use the same account, until
+// the binding goes unused for the binding TTL.
+type Pool struct {
+	mu       sync.Mutex
+	members  []*member
+	strategy Strategy
+	next     int
+	bindings map[string]*binding
+	pruned   time.Time
+
+	clientOptions []gladia.ClientOption
+	bindingTTL    time.Duration
+	cooldown      time.Duration
+	authCooldown  time.Duration
+	now           func() time.Time
+}
+
+// binding ties a transcription ID or audio URL to a member
+type binding struct {
+	member *member
+	used   time.Time
+}
+
+// Option configures a Pool
+type Option func(*Pool)
+
+// WithStrategy sets how members are picked. Defaults to RoundRobin.
+func WithStrategy(strategy Strategy) Option {
+	return func(p *Pool) {
+		p.strategy = strategy
+	}
+}
+
+// WithClientOptions sets the options of clients created by AddKey and Rotate
+func WithClientOptions(opts ...gladia.ClientOption) Option {
+	return func(p *Pool) {
+		p.clientOptions = opts
+	}
+}
+
+// WithCooldown sets how long a member is skipped after being rate limited, and after its
+// key is rejected or out of credit. Rotating a key ends its cooldown.
+// Defaults to 30 seconds and 10 minutes.
+func WithCooldown(rateLimited, rejected time.Duration) Option {
+	return func(p *Pool) {
+		p.cooldown = rateLimited
+		p.authCooldown = rejected
+	}
+}
+
+// WithBindingTTL sets how long a binding unused by any call is kept. A zero ttl keeps
+// bindings until they are forgotten. Defaults to 24 hours.
+func WithBindingTTL(ttl time.Duration) Option {
+	return func(p *Pool) {
+		p.bindingTTL = ttl
+	}
+}
+
+// New creates an empty pool
+func New(opts ...Option) *Pool {
+	p := &Pool{
+		bindings:     make(map[string]*binding),
+		bindingTTL:   defaultBindingTTL,
+		cooldown:     30 * time.Second,
+		authCooldown: 10 * time.Minute,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Add adds a client under name with a weight used by the Weighted strategy.
+// Adding an existing name replaces its client, as Rotate does.
+func (p *Pool) Add(name string, client *gladia.Client, weight int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if m := p.find(name); m != nil {
+		m.mu.Lock()
+		m.client, m.coolUntil = client, time.Time{}
+		m.weight = max(weight, 1)
+		m.mu.Unlock()
+		return
+	}
+	p.members = append(p.members, &member{name: name, client: client, weight: max(weight, 1)})
+}
+
+// AddKey adds a client for apiKey, created with the pool's client options
+func (p *Pool) AddKey(name, apiKey string, weight int) {
+	p.Add(name, gladia.NewClient(apiKey, p.clientOptions...), weight)
+}
+
+// Rotate replaces the API key of a member without disturbing calls in progress.
+// Transcriptions bound to the member use the new key from then on.
+func (p *Pool) Rotate(name, apiKey string) error {
+	p.mu.Lock()
+	m := p.find(name)
+	p.mu.Unlock()
+	if m == nil {
+		return fmt.Errorf("no client named %q", name)
+	}
+
+	client := gladia.NewClient(apiKey, p.clientOptions...)
+	m.mu.Lock()
+	m.client, m.coolUntil = client, time.Time{}
+	m.mu.Unlock()
+	return nil
+}
+
+// Remove stops picking a member for new requests. Transcriptions it created remain
+// reachable through it.
+func (p *Pool) Remove(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, m := range p.members {
+		if m.name == name {
+			p.members = append(p.members[:i], p.members[i+1:]...)
+			return
+		}
+	}
+}
+
+// Names returns the names of the members, in the order they were added
+func (p *Pool) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	names := make([]string, len(p.members))
+	for i, m := range p.members {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Owner returns the name of the member bound to a transcription ID or audio URL
+func (p *Pool) Owner(id string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if b, ok := p.bindings[id]; ok {
+		return b.member.name, true
+	}
+	return "", false
+}
+
+// Bind records that a transcription ID or audio URL belongs to the named member, for
+// example when restoring jobs created before a restart
+func (p *Pool) Bind(id, name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	m := p.find(name)
+	if m == nil {
+		return fmt.Errorf("no client named %q", name)
+	}
+	p.setBinding(id, m)
+	return nil
+}
+
+// Forget drops the binding of a transcription ID or audio URL
+func (p *Pool) Forget(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.bindings, id)
+}
+
+func (p *Pool) find(name string) *member {
+	for _, m := range p.members {
+		if m.name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func (p *Pool) bind(id string, m *member) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setBinding(id, m)
+}
+
+// setBinding binds id to m, pruning expired bindings. The caller must hold p.mu.
+func (p *Pool) setBinding(id string, m *member) {
+	now := p.now()
+	p.prune(now)
+	p.bindings[id] = &binding{member: m, used: now}
+}
+
+// bound returns the member bound to id and marks the binding as used
+func (p *Pool) bound(id string) *member {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	p.prune(now)
+	b, ok := p.bindings[id]
+	if !ok {
+		return nil
+	}
+	b.used = now
+	return b.member
+}
+
+// prune drops bindings unused for the binding TTL, at most once a minute. The caller
+// must hold p.mu.
+func (p *Pool) prune(now time.Time) {
+	if p.bindingTTL <= 0 || now.Sub(p.pruned) < time.Minute {
+		return
+	}
+	p.pruned = now
+	for id, b := range p.bindings {
+		if now.Sub(b.used) > p.bindingTTL {
+			delete(p.bindings, id)
+		}
+	}
+}
+
+// candidates returns every member in the order they should be tried: the strategy's pick
+// among members not cooling down, then the others
+func (p *Pool) candidates() []*member {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var ready, cooling []*member
+	for _, m := range p.members {
+		m.mu.Lock()
+		cool := now.Before(m.coolUntil)
+		m.mu.Unlock()
+		if cool {
+			cooling = append(cooling, m)
+		} else {
+			ready = append(ready, m)
+		}
+	}
+
+	order := make([]*member, 0, len(p.members))
+	if len(ready) > 0 {
+		first := p.pick(ready)
+		order = append(order, ready[first])
+		order = append(order, ready[first+1:]...)
+		order = append(order, ready[:first]...)
+	}
+	return append(order, cooling...)
+}
+
+// pick returns the index of the member serving the next request
+func (p *Pool) pick(ready []*member) int {
+	switch p.strategy {
+	case LeastInFlight:
+		best, bestLoad := 0, -1
+		for i, m := range ready {
+			m.mu.Lock()
+			load := m.inFlight
+			m.mu.Unlock()
+			if bestLoad < 0 || load < bestLoad {
+				best, bestLoad = i, load
+			}
+		}
+		return best
+	case Weighted:
+		// Smooth weighted round-robin: every member gains its weight, the richest is
+		// picked and pays back the total
+		best, total := 0, 0
+		for i, m := range ready {
+			m.current += m.weight
+			total += m.weight
+			if m.current > ready[best].current {
+				best = i
+			}
+		}
+		ready[best].current -= total
+		return best
+	default:
+		p.next++
+		return (p.next - 1) % len(ready)
+	}
+}
+
+// failover reports whether err means another member should be tried, putting the member
+// in cooldown
+func (p *Pool) failover(m *member, err error) bool {
+	code, ok := gladiaerrors.StatusCode(err)
+	if !ok {
+		return false
+	}
+
+	var cooldown time.Duration
+	switch code {
+	case http.StatusTooManyRequests:
+		cooldown = p.cooldown
+	case http.StatusUnauthorized, http.StatusPaymentRequired:
+		cooldown = p.authCooldown
+	default:
+		return false
+	}
+
+	m.mu.Lock()
+	m.coolUntil = p.now().Add(cooldown)
+	m.mu.Unlock()
+	return true
+}
+
+// try runs call on members in candidate order until one succeeds or fails for a reason
+// other than its key
+func (p *Pool) try(call func(m *member, client *gladia.Client) error) (*member, error) {
+	candidates := p.candidates()
+	if len(candidates) == 0 {
+		return nil, ErrEmpty
+	}
+
+	var errs []error
+	for _, m := range candidates {
+		err := p.run(m, call)
+		if err == nil {
+			return m, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		if !p.failover(m, err) {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// run calls a member, counting the call as in flight
+func (p *Pool) run(m *member, call func(m *member, client *gladia.Client) error) error {
+	m.mu.Lock()
+	m.inFlight++
+	client := m.client
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		m.inFlight--
+		m.mu.Unlock()
+	}()
+	return call(m, client)
+}
+
+// owner returns the member bound to id, or the first member holding it when the binding
+// is unknown
+func (p *Pool) owner(ctx context.Context, id string) (*member, error) {
+	if m := p.bound(id); m != nil {
+		return m, nil
+	}
+
+	p.mu.Lock()
+	members := append([]*member(nil), p.members...)
+	p.mu.Unlock()
+	if len(members) == 0 {
+		return nil, ErrEmpty
+	}
+
+	var errs []error
+	for _, m := range members {
+		_, err := m.get().GetTranscriptionStatus(ctx, id)
+		if err == nil {
+			p.bind(id, m)
+			return m, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		if code, ok := gladiaerrors.StatusCode(err); !ok || code != http.StatusNotFound {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// UploadFile uploads an audio file through the next member
+func (p *Pool) UploadFile(ctx context.Context, filePath string) (*gladia.UploadResponse, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return p.UploadReader(ctx, filepath.Base(filePath), file)
+}
+
+// UploadReader uploads audio through the next member, failing over to others. The audio
+// URL is bound to the member that uploaded it. Audio read from an io.Seeker is streamed
+// and rewound before each further attempt; other readers are read into memory first so a
+// failed attempt can be repeated.
+func (p *Pool) UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error) {
+	seeker, start, err := rewindable(r)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := 0
+	var upload *gladia.UploadResponse
+	m, err := p.try(func(m *member, client *gladia.Client) error {
+		attempts++
+		if attempts > 1 {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to rewind audio: %w", err)
+			}
+		}
+		var err error
+		upload, err = client.UploadReader(ctx, filename, seeker)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.bind(upload.AudioURL, m)
+	return upload, nil
+}
+
+// rewindable returns r as a seeker with its current offset, reading it into memory when
+// it cannot seek
+func rewindable(r io.Reader) (io.ReadSeeker, int64, error) {
+	if seeker, ok := r.(io.ReadSeeker); ok {
+		// Pipes and sockets implement io.Seeker but fail to seek
+		if start, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			return seeker, start, nil
+		}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read audio: %w", err)
+	}
+	return bytes.NewReader(data), 0, nil
+}
+
+// Transcribe starts a transcription of audioURL
+func (p *Pool) Transcribe(ctx context.Context, audioURL string) (*gladia.TranscriptionResponse, error) {
+	return p.TranscribeWithRequest(ctx, &gladia.TranscriptionRequest{AudioURL: audioURL})
+}
+
+// TranscribeWithRequest starts a transcription and binds its ID to the member that
+// created it. Audio uploaded through the pool belongs to the account that uploaded it, so
+// it is transcribed by that member without failing over to others.
+func (p *Pool) TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error) {
+	var job *gladia.TranscriptionResponse
+	call := func(m *member, client *gladia.Client) error {
+		var err error
+		job, err = client.TranscribeWithRequest(ctx, req)
+		return err
+	}
+
+	m := p.bound(req.AudioURL)
+	var err error
+	if m != nil {
+		if err = p.run(m, call); err != nil {
+			err = fmt.Errorf("%s: %w", m.name, err)
+		}
+	} else {
+		m, err = p.try(call)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.bind(job.ID, m)
+	return job, nil
+}
+
+// GetTranscriptionStatus returns the status of a transcription from its owner
+func (p *Pool) GetTranscriptionStatus(ctx context.Context, transcriptionID string) (*gladia.GetTranscriptionStatus, error) {
+	m, err := p.owner(ctx, transcriptionID)
+	if err != nil {
+		return nil, err
+	}
+	var status *gladia.GetTranscriptionStatus
+	err = p.run(m, func(m *member, client *gladia.Client) error {
+		status, err = client.GetTranscriptionStatus(ctx, transcriptionID)
+		return err
+	})
+	return status, err
+}
+
+// GetTranscriptionResult returns the result of a transcription from its owner
+func (p *Pool) GetTranscriptionResult(ctx context.Context, transcriptionID string) (*gladia.CompletedTranscriptionResult, error) {
+	m, err := p.owner(ctx, transcriptionID)
+	if err != nil {
+		return nil, err
+	}
+	var result *gladia.CompletedTranscriptionResult
+	err = p.run(m, func(m *member, client *gladia.Client) error {
+		result, err = client.GetTranscriptionResult(ctx, transcriptionID)
+		return err
+	})
+	return result, err
+}
+
+// WaitForTranscription waits for a transcription through its owner
+func (p *Pool) WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error) {
+	m, err := p.owner(ctx, transcriptionID)
+	if err != nil {
+		return nil, err
+	}
+	var result *gladia.CompletedTranscriptionResult
+	err = p.run(m, func(m *member, client *gladia.Client) error {
+		result, err = client.WaitForTranscription(ctx, transcriptionID, opts...)
+		return err
+	})
+	return result, err
+}
+
+// DeleteTranscription deletes a transcription through its owner and drops its binding
+func (p *Pool) DeleteTranscription(ctx context.Context, transcriptionID string) error {
+	m, err := p.owner(ctx, transcriptionID)
+	if err != nil {
+		return err
+	}
+	err = p.run(m, func(m *member, client *gladia.Client) error {
+		return client.DeleteTranscription(ctx, transcriptionID)
+	})
+	if err == nil {
+		p.Forget(transcriptionID)
+	}
+	return err
+}
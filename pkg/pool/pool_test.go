@@ -0,0 +1,246 @@
+package pool
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladiatest"
+)
+
+func newPool(t *testing.T, opts ...Option) (*Pool, map[string]*gladiatest.Server) {
+	t.Helper()
+	p := New(opts...)
+	servers := make(map[string]*gladiatest.Server)
+	for _, name := range []string{"a", "b"} {
+		server := gladiatest.NewServer()
+		t.Cleanup(server.Close)
+		servers[name] = server
+		p.Add(name, server.Client(), 1)
+	}
+	return p, servers
+}
+
+func TestFailover(t *testing.T) {
+	p, servers := newPool(t)
+	ctx := context.Background()
+	servers["a"].InjectFault(gladiatest.Fault{Method: http.MethodPost, Path: "/v2/pre-recorded", StatusCode: http.StatusPaymentRequired})
+
+	job, err := p.Transcribe(ctx, "https://example.com/audio.wav")
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if owner, _ := p.Owner(job.ID); owner != "b" {
+		t.Errorf("job owned by %q, want b", owner)
+	}
+}
+
+func TestUploadedAudioDoesNotFailOver(t *testing.T) {
+	p, servers := newPool(t)
+	ctx := context.Background()
+
+	upload, err := p.UploadReader(ctx, "audio.wav", strings.NewReader("RIFF0000WAVE"))
+	if err != nil {
+		t.Fatalf("UploadReader: %v", err)
+	}
+	owner, _ := p.Owner(upload.AudioURL)
+	other := "a"
+	if owner == "a" {
+		other = "b"
+	}
+	servers[owner].InjectFault(gladiatest.Fault{Method: http.MethodPost, Path: "/v2/pre-recorded", StatusCode: http.StatusPaymentRequired})
+
+	if _, err := p.Transcribe(ctx, upload.AudioURL); err == nil {
+		t.Fatal("expected the uploading member's error")
+	}
+	list, err := servers[other].Client().ListTranscriptions(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTranscriptions: %v", err)
+	}
+	if len(list.Items) != 0 {
+		t.Errorf("audio uploaded through %s was sent to %s", owner, other)
+	}
+}
+
+func TestBindingTTL(t *testing.T) {
+	now := time.Now()
+	p, _ := newPool(t, WithBindingTTL(time.Hour))
+	p.now = func() time.Time { return now }
+
+	if err := p.Bind("kept", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Bind("expired", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(50 * time.Minute)
+	p.bound("kept")
+	now = now.Add(20 * time.Minute)
+	p.bind("new", p.find("b"))
+
+	for id, want := range map[string]bool{"kept": true, "expired": false, "new": true} {
+		if _, ok := p.Owner(id); ok != want {
+			t.Errorf("binding %q kept = %v, want %v", id, ok, want)
+		}
+	}
+}
+
+// drainingDoer reads the whole request body and answers it as rate limited
+type drainingDoer struct{}
+
+func (drainingDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+	return &http.Response{
+		Status:     "429 Too Many Requests",
+		StatusCode: http.StatusTooManyRequests,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+func skip(r *strings.Reader, n int64) *strings.Reader {
+	r.Seek(n, io.SeekStart)
+	return r
+}
+
+func TestUploadFailoverRewindsTheAudio(t *testing.T) {
+	server := gladiatest.NewServer()
+	defer server.Close()
+	ctx := context.Background()
+
+	audio := strings.Repeat("RIFF0000WAVE", 1000)
+	tests := []struct {
+		name string
+		r    io.Reader
+	}{
+		{name: "seeker", r: strings.NewReader(audio)},
+		{name: "seeker at an offset", r: skip(strings.NewReader("skip"+audio), 4)},
+		{name: "reader", r: io.MultiReader(strings.NewReader(audio))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.Add("a", gladia.NewClient("key", gladia.WithBaseURL(server.URL+"/"), gladia.WithHTTPClient(drainingDoer{})), 1)
+			p.Add("b", server.Client(), 1)
+
+			upload, err := p.UploadReader(ctx, "audio.wav", tt.r)
+			if err != nil {
+				t.Fatalf("UploadReader: %v", err)
+			}
+			if owner, _ := p.Owner(upload.AudioURL); owner != "b" {
+				t.Errorf("upload owned by %q, want b", owner)
+			}
+			if upload.AudioMetadata.Size != int64(len(audio)) {
+				t.Errorf("uploaded %d bytes, want %d", upload.AudioMetadata.Size, len(audio))
+			}
+		})
+	}
+}
+
+func TestRotate(t *testing.T) {
+	server := gladiatest.NewServer(gladiatest.WithAPIKey("new"))
+	defer server.Close()
+	p := New(WithClientOptions(gladia.WithBaseURL(server.URL + "/")))
+	p.AddKey("a", "old", 1)
+	ctx := context.Background()
+
+	if _, err := p.Transcribe(ctx, "https://example.com/audio.wav"); err == nil {
+		t.Fatal("expected the old key to be rejected")
+	}
+	if err := p.Rotate("a", "new"); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	job, err := p.Transcribe(ctx, "https://example.com/audio.wav")
+	if err != nil {
+		t.Fatalf("Transcribe after Rotate: %v", err)
+	}
+	if _, err := p.GetTranscriptionStatus(ctx, job.ID); err != nil {
+		t.Errorf("GetTranscriptionStatus: %v", err)
+	}
+	if err := p.Rotate("missing", "new"); err == nil {
+		t.Error("Rotate of an unknown member succeeded")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	p, _ := newPool(t)
+	ctx := context.Background()
+
+	job, err := p.Transcribe(ctx, "https://example.com/audio.wav")
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if owner, _ := p.Owner(job.ID); owner != "a" {
+		t.Fatalf("job owned by %q, want a", owner)
+	}
+
+	p.Remove("a")
+	if names := p.Names(); len(names) != 1 || names[0] != "b" {
+		t.Errorf("names = %v, want [b]", names)
+	}
+	for range 3 {
+		next, err := p.Transcribe(ctx, "https://example.com/audio.wav")
+		if err != nil {
+			t.Fatalf("Transcribe: %v", err)
+		}
+		if owner, _ := p.Owner(next.ID); owner != "b" {
+			t.Errorf("job owned by %q after removing a", owner)
+		}
+	}
+	if _, err := p.GetTranscriptionStatus(ctx, job.ID); err != nil {
+		t.Errorf("job of the removed member is unreachable: %v", err)
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		weights  map[string]int
+		inFlight map[string]int
+		want     string
+	}{
+		{name: "round robin", strategy: RoundRobin, want: "abababab"},
+		{name: "least in flight", strategy: LeastInFlight, inFlight: map[string]int{"a": 2, "b": 1}, want: "bbbbbbbb"},
+		{name: "least in flight tie", strategy: LeastInFlight, want: "aaaaaaaa"},
+		{name: "weighted", strategy: Weighted, weights: map[string]int{"a": 3, "b": 1}, want: "aabaaaba"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(WithStrategy(tt.strategy))
+			for _, name := range []string{"a", "b"} {
+				weight := tt.weights[name]
+				p.Add(name, gladia.NewClient("key"), weight)
+				p.find(name).inFlight = tt.inFlight[name]
+			}
+
+			var got strings.Builder
+			for range len(tt.want) {
+				got.WriteString(p.candidates()[0].name)
+			}
+			if got.String() != tt.want {
+				t.Errorf("picks = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCoolingMembersAreTriedLast(t *testing.T) {
+	p, servers := newPool(t, WithStrategy(LeastInFlight))
+	ctx := context.Background()
+	servers["a"].InjectFault(gladiatest.Fault{Method: http.MethodPost, Path: "/v2/pre-recorded", StatusCode: http.StatusTooManyRequests, Times: 1})
+
+	if _, err := p.Transcribe(ctx, "https://example.com/audio.wav"); err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if first := p.candidates()[0].name; first != "b" {
+		t.Errorf("first candidate = %s, want b while a cools down", first)
+	}
+}
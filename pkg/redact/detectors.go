@@ -0,0 +1,139 @@
+package redact
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Span is a sensitive range of text found by a detector, as byte offsets
+type Span struct {
+	Start int
+	End   int
+	Kind  string
+}
+
+// Detector finds sensitive spans in text
+type Detector interface {
+	Find(text string) []Span
+}
+
+// RegexDetector reports matches of Pattern accepted by Validate
+type RegexDetector struct {
+	Kind    string
+	Pattern *regexp.Regexp
+	// Validate filters out false positives; nil accepts every match
+	Validate func(match string) bool
+}
+
+// Find returns the validated matches of the pattern in text
+func (d *RegexDetector) Find(text string) []Span {
+	var spans []Span
+	for _, loc := range d.Pattern.FindAllStringIndex(text, -1) {
+		if d.Validate != nil && !d.Validate(text[loc[0]:loc[1]]) {
+			continue
+		}
+		spans = append(spans, Span{Start: loc[0], End: loc[1], Kind: d.Kind})
+	}
+	return spans
+}
+
+// Email detects email addresses
+func Email() Detector {
+	return &RegexDetector{
+		Kind:    "EMAIL",
+		Pattern: regexp.MustCompile(`(?i)\b[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}\b`),
+	}
+}
+
+// Phone detects phone numbers of 7 to 15 digits, optionally international and grouped
+func Phone() Detector {
+	return &RegexDetector{
+		Kind:    "PHONE",
+		Pattern: regexp.MustCompile(`(?:\+|\b00)?\(?\d[\d\s().\-]{5,}\d\b`),
+		Validate: func(match string) bool {
+			n := countDigits(match)
+			return n >= 7 && n <= 15
+		},
+	}
+}
+
+// IBAN detects international bank account numbers with a valid checksum
+func IBAN() Detector {
+	return &RegexDetector{
+		Kind:     "IBAN",
+		Pattern:  regexp.MustCompile(`(?i)\b[a-z]{2}\d{2}(?:\s?[a-z0-9]{4}){2,7}(?:\s?[a-z0-9]{1,4})?\b`),
+		Validate: validIBAN,
+	}
+}
+
+// CreditCard detects payment card numbers with a valid Luhn checksum
+func CreditCard() Detector {
+	return &RegexDetector{
+		Kind:     "CREDIT_CARD",
+		Pattern:  regexp.MustCompile(`\b\d(?:[\s\-]?\d){12,18}\b`),
+		Validate: validLuhn,
+	}
+}
+
+// DefaultDetectors returns the built-in email, phone, IBAN and credit card detectors.
+// Card and IBAN detectors come first so their numbers are not reported as phones.
+func DefaultDetectors() []Detector {
+	return []Detector{CreditCard(), IBAN(), Email(), Phone()}
+}
+
+func countDigits(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsDigit(r) {
+			n++
+		}
+	}
+	return n
+}
+
+func validLuhn(number string) bool {
+	sum, double, digits := 0, false, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+func validIBAN(iban string) bool {
+	iban = strings.ToUpper(strings.ReplaceAll(iban, " ", ""))
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	rearranged := iban[4:] + iban[:4]
+	var numeric strings.Builder
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			numeric.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			numeric.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
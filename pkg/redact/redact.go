@@ -0,0 +1,479 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// SourceNamedEntity marks findings that come from named entity recognition results
+const SourceNamedEntity = "named_entity_recognition"
+
+// SourceDetector marks findings that come from a local detector
+const SourceDetector = "detector"
+
+var wordChar = regexp.MustCompile(`\w`)
+
+// Finding records a redacted occurrence. The original text is not kept; with a digest key
+// a keyed digest lets auditors correlate occurrences of the same value, see WithDigestKey.
+type Finding struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	// Location is a path such as "utterances[3]" or "translations[0].full_transcript"
+	Location string `json:"location"`
+	// Offset and Length locate the occurrence in the original text at Location, in bytes
+	Offset int `json:"offset"`
+	Length int `json:"length"`
+	// Start and End are the audio positions of the occurrence, when known
+	Start  float64 `json:"start,omitempty"`
+	End    float64 `json:"end,omitempty"`
+	Digest string  `json:"digest,omitempty"`
+}
+
+// Redactor masks personal data in transcription results
+type Redactor struct {
+	detectors   []Detector
+	entities    bool
+	entityTypes map[string]bool
+	mask        func(kind string) string
+	digestKey   []byte
+}
+
+// Option configures a Redactor
+type Option func(*Redactor)
+
+// WithDetectors replaces the default local detectors
+func WithDetectors(detectors ...Detector) Option {
+	return func(r *Redactor) {
+		r.detectors = detectors
+	}
+}
+
+// WithEntityTypes limits redaction of named entities to the given types. By default
+// every entity found by named entity recognition is redacted.
+func WithEntityTypes(types ...string) Option {
+	return func(r *Redactor) {
+		r.entityTypes = make(map[string]bool, len(types))
+		for _, t := range types {
+			r.entityTypes[strings.ToUpper(t)] = true
+		}
+	}
+}
+
+// WithoutEntities ignores named entity recognition results
+func WithoutEntities() Option {
+	return func(r *Redactor) {
+		r.entities = false
+	}
+}
+
+// WithMask sets the replacement text of a redacted span, by default "[KIND]"
+func WithMask(mask func(kind string) string) Option {
+	return func(r *Redactor) {
+		r.mask = mask
+	}
+}
+
+// WithDigestKey records in each finding an HMAC-SHA256 of the value keyed with key, so
+// occurrences of the same value can be correlated without being recoverable by whoever
+// reads the findings. Keep the key secret. Without a key findings carry no digest.
+func WithDigestKey(key []byte) Option {
+	return func(r *Redactor) {
+		r.digestKey = key
+	}
+}
+
+// New creates a Redactor using the default detectors and named entities
+func New(opts ...Option) *Redactor {
+	r := &Redactor{
+		detectors: DefaultDetectors(),
+		entities:  true,
+		mask: func(kind string) string {
+			return "[" + kind + "]"
+		},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+type term struct {
+	kind   string
+	source string
+}
+
+// Redact returns a redacted copy of result and the list of what was removed.
+// The same value is masked in every free-text field: transcript, utterances, words,
+// subtitles, sentences, translations, the results of audio intelligence features such as
+// named entities, chapters, summary and audio to LLM, custom metadata, and the prompts,
+// vocabulary and spelling dictionary of the request. Named entities selected for
+// redaction are listed with their text replaced by the mask.
+func (r *Redactor) Redact(result *gladia.CompletedTranscriptionResult) (*gladia.CompletedTranscriptionResult, []Finding, error) {
+	redacted, err := clone(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	data := &redacted.Result
+
+	translations, err := data.Translations()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms, err := r.collect(redacted, translations)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(terms) == 0 {
+		return redacted, nil, nil
+	}
+
+	job := &redaction{redactor: r, terms: terms, pattern: termPattern(terms)}
+	visitor{text: job.text, words: job.words}.result(redacted, translations)
+	if len(translations) > 0 {
+		data.Translation.Results = translations
+	}
+
+	return redacted, job.findings, nil
+}
+
+// collect gathers the sensitive values to redact, keyed by their lowercase text
+func (r *Redactor) collect(result *gladia.CompletedTranscriptionResult, translations []gladia.TranslationResult) (map[string]term, error) {
+	terms := make(map[string]term)
+
+	if r.entities {
+		entities, err := result.Result.NamedEntities()
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range entities {
+			kind := strings.ToUpper(entity.EntityType)
+			text := strings.TrimSpace(entity.Text)
+			if text == "" || (r.entityTypes != nil && !r.entityTypes[kind]) {
+				continue
+			}
+			terms[strings.ToLower(text)] = term{kind: kind, source: SourceNamedEntity}
+		}
+	}
+
+	var texts []string
+	visitor{text: func(s, location string, start, end float64) string {
+		if s != "" {
+			texts = append(texts, s)
+		}
+		return s
+	}}.result(result, translations)
+
+	for _, text := range texts {
+		claimed := make([]Span, 0)
+		for _, detector := range r.detectors {
+			for _, span := range detector.Find(text) {
+				if overlaps(claimed, span) {
+					continue
+				}
+				claimed = append(claimed, span)
+				key := strings.ToLower(text[span.Start:span.End])
+				if _, ok := terms[key]; !ok {
+					terms[key] = term{kind: span.Kind, source: SourceDetector}
+				}
+			}
+		}
+	}
+
+	return terms, nil
+}
+
+type redaction struct {
+	redactor *Redactor
+	terms    map[string]term
+	pattern  *regexp.Regexp
+	findings []Finding
+}
+
+// visitor rewrites every free-text field of a result. Fields are visited in a fixed order
+// so findings are reported deterministically.
+type visitor struct {
+	// text rewrites a field found at location, spoken between start and end when known
+	text func(s, location string, start, end float64) string
+	// words rewrites the words of an utterance; nil leaves them untouched
+	words func(words []gladia.Word, location string) []gladia.Word
+}
+
+func (v visitor) result(result *gladia.CompletedTranscriptionResult, translations []gladia.TranslationResult) {
+	data := &result.Result
+	v.transcription(&data.Transcription, "")
+	for i := range translations {
+		t := &translations[i]
+		td := gladia.TranscriptionData{
+			FullTranscript: t.FullTranscript,
+			Sentences:      t.Sentences,
+			Subtitles:      t.Subtitles,
+			Utterances:     t.Utterances,
+		}
+		v.transcription(&td, fmt.Sprintf("translations[%d].", i))
+		t.FullTranscript, t.Sentences, t.Subtitles, t.Utterances = td.FullTranscript, td.Sentences, td.Subtitles, td.Utterances
+	}
+
+	processing := []struct {
+		name   string
+		result *gladia.ProcessingResult
+	}{
+		{"summarization", &data.Summarization},
+		{"moderation", &data.Moderation},
+		{"named_entity_recognition", &data.NamedEntityRecognition},
+		{"name_consistency", &data.NameConsistency},
+		{"custom_spelling", &data.CustomSpelling},
+		{"speaker_reidentification", &data.SpeakerReidentification},
+		{"structured_data_extraction", &data.StructuredDataExtraction},
+		{"sentiment_analysis", &data.SentimentAnalysis},
+		{"sentences", &data.Sentences},
+		{"display_mode", &data.DisplayMode},
+		{"chapters", &data.Chapters},
+	}
+	for _, p := range processing {
+		p.result.Results = v.value(p.result.Results, p.name+".results")
+	}
+	for i := range data.AudioToLLM.Results {
+		prompt := &data.AudioToLLM.Results[i].Results
+		location := fmt.Sprintf("audio_to_llm.results[%d]", i)
+		prompt.Prompt = v.text(prompt.Prompt, location+".prompt", 0, 0)
+		prompt.Response = v.text(prompt.Response, location+".response", 0, 0)
+	}
+
+	v.metadata(result.CustomMetadata, "custom_metadata")
+	v.request(&result.RequestParams)
+	result.File.Filename = v.text(result.File.Filename, "file.filename", 0, 0)
+}
+
+func (v visitor) transcription(td *gladia.TranscriptionData, prefix string) {
+	td.FullTranscript = v.text(td.FullTranscript, prefix+"full_transcript", 0, 0)
+	for i := range td.Utterances {
+		u := &td.Utterances[i]
+		location := fmt.Sprintf("%sutterances[%d]", prefix, i)
+		u.Text = v.text(u.Text, location, u.Start, u.End)
+		if v.words != nil {
+			u.Words = v.words(u.Words, location+".words")
+		}
+	}
+	for i := range td.Subtitles {
+		s := &td.Subtitles[i]
+		s.Subtitles = v.text(s.Subtitles, fmt.Sprintf("%ssubtitles[%d]", prefix, i), 0, 0)
+	}
+	for i := range td.Sentences {
+		sentences := td.Sentences[i].Results
+		for j := range sentences {
+			sentences[j] = v.text(sentences[j], fmt.Sprintf("%ssentences[%d].results[%d]", prefix, i, j), 0, 0)
+		}
+	}
+}
+
+func (v visitor) request(req *gladia.TranscriptionRequest) {
+	req.ContextPrompt = v.text(req.ContextPrompt, "request_params.context_prompt", 0, 0)
+	if c := req.CustomVocabularyConfig; c != nil {
+		if vocabulary := c.Vocabulary.RealtimeProcessing.CustomVocabularyConfig; vocabulary != nil {
+			v.value(vocabulary.Vocabulary, "request_params.custom_vocabulary_config.vocabulary")
+		}
+	}
+	if c := req.CustomSpellingConfig; c != nil && c.SpellingDictionary != nil {
+		// The dictionary is keyed by the spelling to enforce, often a name
+		dictionary := make(map[string][]string, len(c.SpellingDictionary))
+		for n, spelling := range slices.Sorted(maps.Keys(c.SpellingDictionary)) {
+			// Locations are positional so they do not repeat the spelling
+			location := fmt.Sprintf("request_params.custom_spelling_config.spelling_dictionary[%d]", n)
+			variants := c.SpellingDictionary[spelling]
+			for i := range variants {
+				variants[i] = v.text(variants[i], fmt.Sprintf("%s[%d]", location, i), 0, 0)
+			}
+			key := v.text(spelling, location, 0, 0)
+			dictionary[key] = append(dictionary[key], variants...)
+		}
+		c.SpellingDictionary = dictionary
+	}
+	if c := req.StructuredDataExtrConfig; c != nil {
+		for i := range c.Classes {
+			c.Classes[i] = v.text(c.Classes[i], fmt.Sprintf("request_params.structured_data_extraction_config.classes[%d]", i), 0, 0)
+		}
+	}
+	if c := req.AudioToLLMConfig; c != nil {
+		for i := range c.Prompts {
+			c.Prompts[i] = v.text(c.Prompts[i], fmt.Sprintf("request_params.audio_to_llm_config.prompts[%d]", i), 0, 0)
+		}
+	}
+	v.metadata(req.CustomMetadata, "request_params.custom_metadata")
+}
+
+func (v visitor) metadata(metadata gladia.CustomMetadata, location string) {
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		metadata[key] = v.value(metadata[key], location+"."+key)
+	}
+}
+
+// value rewrites the strings of a decoded JSON value, in place for objects and arrays
+func (v visitor) value(value any, location string) any {
+	switch value := value.(type) {
+	case string:
+		return v.text(value, location, 0, 0)
+	case []any:
+		for i := range value {
+			value[i] = v.value(value[i], fmt.Sprintf("%s[%d]", location, i))
+		}
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			value[key] = v.value(value[key], location+"."+key)
+		}
+	}
+	return value
+}
+
+// text masks every sensitive value in s, recording a finding per occurrence
+func (j *redaction) text(s, location string, start, end float64) string {
+	matches := j.pattern.FindAllStringIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		value := s[m[0]:m[1]]
+		t := j.terms[strings.ToLower(value)]
+		j.findings = append(j.findings, Finding{
+			Kind:     t.kind,
+			Source:   t.source,
+			Location: location,
+			Offset:   m[0],
+			Length:   m[1] - m[0],
+			Start:    start,
+			End:      end,
+			Digest:   j.redactor.digest(value),
+		})
+		b.WriteString(s[last:m[0]])
+		b.WriteString(j.redactor.mask(t.kind))
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// words masks sensitive values spanning one or more words. The words covering a value
+// are collapsed into a single masked word that keeps the first word's start and the last
+// word's end, so word timings stay consistent with the utterance text.
+func (j *redaction) words(words []gladia.Word, location string) []gladia.Word {
+	if len(words) == 0 {
+		return words
+	}
+
+	var joined strings.Builder
+	offsets := make([]int, len(words)+1)
+	for i, w := range words {
+		offsets[i] = joined.Len()
+		joined.WriteString(w.Word)
+	}
+	offsets[len(words)] = joined.Len()
+
+	matches := j.pattern.FindAllStringIndex(joined.String(), -1)
+	if len(matches) == 0 {
+		return words
+	}
+
+	var redacted []gladia.Word
+	next := 0
+	for _, m := range matches {
+		first := sort.Search(len(words), func(i int) bool { return offsets[i+1] > m[0] })
+		last := sort.Search(len(words), func(i int) bool { return offsets[i+1] >= m[1] })
+		if first < next || first >= len(words) {
+			continue
+		}
+		last = min(last, len(words)-1)
+		redacted = append(redacted, words[next:first]...)
+
+		text := joined.String()
+		value := text[m[0]:m[1]]
+		t := j.terms[strings.ToLower(value)]
+		word := words[first]
+		word.Word = text[offsets[first]:m[0]] + j.redactor.mask(t.kind) + text[m[1]:offsets[last+1]]
+		word.End = words[last].End
+		redacted = append(redacted, word)
+
+		j.findings = append(j.findings, Finding{
+			Kind:     t.kind,
+			Source:   t.source,
+			Location: fmt.Sprintf("%s[%d]", location, first),
+			Offset:   m[0] - offsets[first],
+			Length:   m[1] - m[0],
+			Start:    words[first].Start,
+			End:      words[last].End,
+			Digest:   j.redactor.digest(value),
+		})
+		next = last + 1
+	}
+	return append(redacted, words[next:]...)
+}
+
+// termPattern builds a case-insensitive pattern matching any term, longest first
+func termPattern(terms map[string]term) *regexp.Regexp {
+	values := make([]string, 0, len(terms))
+	for value := range terms {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+
+	// Anchor terms on word boundaries so a name does not match inside a longer word
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = regexp.QuoteMeta(value)
+		if wordChar.MatchString(value[:1]) {
+			quoted[i] = `\b` + quoted[i]
+		}
+		if wordChar.MatchString(value[len(value)-1:]) {
+			quoted[i] += `\b`
+		}
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+func overlaps(spans []Span, span Span) bool {
+	for _, s := range spans {
+		if span.Start < s.End && s.Start < span.End {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) digest(value string) string {
+	if len(r.digestKey) == 0 {
+		return ""
+	}
+	mac := hmac.New(sha256.New, r.digestKey)
+	mac.Write([]byte(strings.ToLower(value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// clone deep copies a result through its JSON representation
+func clone(result *gladia.CompletedTranscriptionResult) (*gladia.CompletedTranscriptionResult, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy result: %w", err)
+	}
+	var copied gladia.CompletedTranscriptionResult
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("failed to copy result: %w", err)
+	}
+	return &copied, nil
+}
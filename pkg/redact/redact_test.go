@@ -0,0 +1,153 @@
+package redact
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// fixture places personal data in every free-text field of a result. The last address
+// only appears in an audio to LLM response, so it must be found by the detectors there.
+const fixture = `{
+	"id": "job-1",
+	"status": "done",
+	"custom_metadata": {"customer": "Jane Doe", "contacts": ["jane@example.com"]},
+	"file": {"filename": "jane@example.com.wav"},
+	"request_params": {
+		"audio_url": "https://example.com/audio.wav",
+		"context_prompt": "Support call with Jane Doe",
+		"custom_vocabulary_config": {"vocabulary": {"realtime_processing": {"custom_vocabulary_config": {
+			"vocabulary": ["Jane Doe", {"value": "Jane Doe", "intensity": 0.5}]
+		}}}},
+		"custom_spelling_config": {"spelling_dictionary": {"Jane Doe": ["jane doe", "jean doe"]}},
+		"structured_data_extraction_config": {"classes": ["Jane Doe"]},
+		"audio_to_llm_config": {"prompts": ["What did Jane Doe ask?"]},
+		"custom_metadata": {"customer": "Jane Doe"}
+	},
+	"result": {
+		"transcription": {
+			"full_transcript": "Hi, this is Jane Doe, my email is jane@example.com.",
+			"sentences": [{"success": true, "results": ["Hi, this is Jane Doe."]}],
+			"subtitles": [{"format": "srt", "subtitles": "1\n00:00:00,000 --> 00:00:02,000\nHi, this is Jane Doe"}],
+			"utterances": [{
+				"start": 0, "end": 3, "text": "Hi, this is Jane Doe, my email is jane@example.com.",
+				"words": [
+					{"word": "Hi,", "start": 0, "end": 0.3},
+					{"word": " this", "start": 0.3, "end": 0.5},
+					{"word": " is", "start": 0.5, "end": 0.6},
+					{"word": " Jane", "start": 0.6, "end": 0.9},
+					{"word": " Doe,", "start": 0.9, "end": 1.2},
+					{"word": " my", "start": 1.2, "end": 1.4},
+					{"word": " email", "start": 1.4, "end": 1.7},
+					{"word": " is", "start": 1.7, "end": 1.8},
+					{"word": " jane@example.com.", "start": 1.8, "end": 3}
+				]
+			}]
+		},
+		"translation": {"success": true, "results": [{
+			"full_transcript": "Bonjour, ici Jane Doe.",
+			"sentences": [{"success": true, "results": ["Bonjour, ici Jane Doe."]}],
+			"utterances": [{"start": 0, "end": 3, "text": "Bonjour, ici Jane Doe."}]
+		}]},
+		"summarization": {"success": true, "results": "Jane Doe asked for a refund."},
+		"named_entity_recognition": {"success": true, "results": [{"entity_type": "PERSON", "text": "Jane Doe", "start": 0.6, "end": 1.2}]},
+		"name_consistency": {"success": true, "results": [{"entity_type": "PERSON", "text": "Jane Doe"}]},
+		"chapters": {"success": true, "results": [{"headline": "Jane Doe calls", "summary": "Jane Doe wants a refund", "gist": "Jane Doe", "keywords": ["Jane Doe"], "start": 0, "end": 3}]},
+		"sentences": {"success": true, "results": ["Hi, this is Jane Doe."]},
+		"structured_data_extraction": {"success": true, "results": {"customer": {"name": "Jane Doe", "email": "jane@example.com"}}},
+		"sentiment_analysis": {"success": true, "results": [{"text": "Hi, this is Jane Doe", "sentiment": "neutral"}]},
+		"audio_to_llm": {"success": true, "results": [{"success": true, "results": {
+			"prompt": "What did Jane Doe ask?",
+			"response": "Jane Doe asked for a refund; follow up at jdoe@corp.example."
+		}}]}
+	}
+}`
+
+func TestRedactLeavesNoDetectedValue(t *testing.T) {
+	var result gladia.CompletedTranscriptionResult
+	if err := json.Unmarshal([]byte(fixture), &result); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	redacted, findings, err := New().Redact(&result)
+	if err != nil {
+		t.Fatalf("Redact: %v", err)
+	}
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := strings.ToLower(string(data))
+
+	for _, value := range []string{"jane doe", "jane@example.com", "jdoe@corp.example"} {
+		if strings.Contains(out, value) {
+			t.Errorf("%q survived redaction:\n%s", value, data)
+		}
+	}
+	for _, mask := range []string{"[person]", "[email]"} {
+		if !strings.Contains(out, mask) {
+			t.Errorf("redacted result has no %s mask", mask)
+		}
+	}
+
+	// The redacted result keeps its shape and its non-sensitive content
+	if redacted.ID != "job-1" || redacted.RequestParams.AudioURL != "https://example.com/audio.wav" {
+		t.Errorf("redaction changed structural fields: %+v", redacted)
+	}
+	words := redacted.Result.Transcription.Utterances[0].Words
+	if len(words) != 8 || words[3].Word != " [PERSON]," || words[3].Start != 0.6 || words[3].End != 1.2 {
+		t.Errorf("unexpected redacted words %+v", words)
+	}
+	if len(findings) == 0 {
+		t.Error("no findings reported")
+	}
+	for _, f := range findings {
+		if strings.Contains(strings.ToLower(f.Location), "jane") {
+			t.Errorf("finding location %q leaks a redacted value", f.Location)
+		}
+	}
+
+	// The input is left untouched
+	if result.RequestParams.ContextPrompt != "Support call with Jane Doe" {
+		t.Errorf("Redact modified its input: %q", result.RequestParams.ContextPrompt)
+	}
+}
+
+func TestDigests(t *testing.T) {
+	var result gladia.CompletedTranscriptionResult
+	if err := json.Unmarshal([]byte(fixture), &result); err != nil {
+		t.Fatal(err)
+	}
+	digests := func(r *Redactor) map[string]string {
+		_, findings, err := r.Redact(&result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byKind := make(map[string]string)
+		for _, f := range findings {
+			if previous, ok := byKind[f.Kind+f.Location]; ok && previous != f.Digest {
+				t.Errorf("%s at %s digested inconsistently", f.Kind, f.Location)
+			}
+			byKind[f.Kind+f.Location] = f.Digest
+		}
+		return byKind
+	}
+
+	for location, digest := range digests(New()) {
+		if digest != "" {
+			t.Errorf("finding %s has digest %q without a digest key", location, digest)
+		}
+	}
+
+	first, second := digests(New(WithDigestKey([]byte("k1")))), digests(New(WithDigestKey([]byte("k2"))))
+	for location, digest := range first {
+		if digest == "" {
+			t.Errorf("finding %s has no digest", location)
+		}
+		if digest == second[location] {
+			t.Errorf("finding %s has the same digest under two keys", location)
+		}
+	}
+}
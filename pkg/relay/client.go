@@ -0,0 +1,173 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+	"github.com/fulviodenza/go-gladia-client/pkg/webhook"
+)
+
+// Client pulls callbacks from a relay
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+	wait       time.Duration
+}
+
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the HTTP client used to reach the relay. It must not time out
+// before the long-poll wait.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken sets the client token the relay requires, see WithClientToken
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithLongPoll sets how long the relay holds a poll open when there is nothing new
+func WithLongPoll(wait time.Duration) ClientOption {
+	return func(c *Client) {
+		c.wait = wait
+	}
+}
+
+// NewClient creates a client of the relay at baseURL
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{},
+		wait:       defaultLongPoll,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Poll returns the callbacks received after sequence number after, waiting for the next
+// one if there are none yet
+func (c *Client) Poll(ctx context.Context, after int64) ([]Record, error) {
+	query := url.Values{}
+	query.Set("after", strconv.FormatInt(after, 10))
+	query.Set("wait", c.wait.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+EventsPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authorize(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to poll relay: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, gladiaerrors.New(resp.StatusCode, "relay poll failed")
+	}
+
+	var records []Record
+	if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
+		return nil, fmt.Errorf("failed to decode relay response: %w", err)
+	}
+	return records, nil
+}
+
+// Stream calls fn with every callback received after sequence number after, as sent by
+// the relay over server-sent events, until ctx is done, the connection drops or fn fails
+func (c *Client) Stream(ctx context.Context, after int64, fn func(Record) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+StreamPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Last-Event-ID", strconv.FormatInt(after, 10))
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to relay: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return gladiaerrors.New(resp.StatusCode, "relay stream failed")
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 32<<20)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var record Record
+			if err := json.Unmarshal([]byte(data.String()), &record); err != nil {
+				return fmt.Errorf("failed to decode relay event: %w", err)
+			}
+			data.Reset()
+			if err := fn(record); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("failed to read relay stream: %w", err)
+	}
+	return ctx.Err()
+}
+
+// Forward long-polls the relay and passes every callback received after sequence number
+// after to handle, for example a gladia.Callbacks registry, until ctx is done.
+// Failed polls are retried after a short pause.
+func (c *Client) Forward(ctx context.Context, after int64, handle webhook.HandlerFunc) error {
+	for {
+		records, err := c.Poll(ctx, after)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, record := range records {
+			if err := handle(ctx, &record.Event); err != nil {
+				return fmt.Errorf("failed to handle callback %d: %w", record.Seq, err)
+			}
+			after = record.Seq
+		}
+	}
+}
+
+func (c *Client) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
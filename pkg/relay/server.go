@@ -0,0 +1,350 @@
+package relay
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/webhook"
+)
+
+// Paths served by the relay
+const (
+	CallbackPath = "/callback"
+	EventsPath   = "/events"
+	StreamPath   = "/events/stream"
+)
+
+const (
+	defaultLongPoll   = 25 * time.Second
+	maxLongPoll       = 60 * time.Second
+	defaultMaxRecords = 1000
+)
+
+// Record is a callback received by the relay. Sequence numbers start at 1 and increase
+// with every callback, so clients resume after the last one they saw. Only the latest
+// records are kept; a client resuming after dropped records gets the oldest kept one next.
+type Record struct {
+	Seq      int64                `json:"seq"`
+	Received time.Time            `json:"received"`
+	Event    gladia.CallbackEvent `json:"event"`
+}
+
+// Server accepts Gladia callbacks and relays them to development clients over long
+// polling or server-sent events
+type Server struct {
+	mu         sync.Mutex
+	records    []Record
+	dropped    int64
+	maxRecords int
+	changed    chan struct{}
+	log        *os.File
+	path       string
+
+	clientToken    string
+	insecure       bool
+	webhookOptions []webhook.HandlerOption
+	mux            *http.ServeMux
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithLogFile persists callbacks to a JSON lines file, reloaded when the relay restarts
+func WithLogFile(path string) Option {
+	return func(s *Server) {
+		s.path = path
+	}
+}
+
+// WithMaxRecords sets how many callbacks are kept for clients, 1000 by default.
+// The log file keeps every callback, but only the latest are reloaded.
+func WithMaxRecords(n int) Option {
+	return func(s *Server) {
+		s.maxRecords = n
+	}
+}
+
+// WithWebhookOptions configures how incoming callbacks are authenticated. A relay that
+// is not created WithInsecure needs webhook.WithSecret or webhook.WithToken.
+func WithWebhookOptions(opts ...webhook.HandlerOption) Option {
+	return func(s *Server) {
+		s.webhookOptions = append(s.webhookOptions, opts...)
+	}
+}
+
+// WithClientToken requires clients reading callbacks to send token as a bearer token
+func WithClientToken(token string) Option {
+	return func(s *Server) {
+		s.clientToken = token
+	}
+}
+
+// WithInsecure lets anyone post and read callbacks, for a relay reachable only locally
+func WithInsecure() Option {
+	return func(s *Server) {
+		s.insecure = true
+		s.webhookOptions = append(s.webhookOptions, webhook.WithInsecure())
+	}
+}
+
+// NewServer creates a relay. Unless the relay is created WithInsecure, clients must
+// authenticate with WithClientToken and callbacks as set by WithWebhookOptions.
+func NewServer(opts ...Option) (*Server, error) {
+	s := &Server{changed: make(chan struct{}), maxRecords: defaultMaxRecords}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.clientToken == "" && !s.insecure {
+		return nil, errors.New("relay needs a client token")
+	}
+	if s.maxRecords <= 0 {
+		return nil, errors.New("relay needs to keep at least one record")
+	}
+	ingest := webhook.NewHandler(s.append, s.webhookOptions...)
+	if err := ingest.Err(); err != nil {
+		return nil, fmt.Errorf("relay cannot authenticate callbacks: %w", err)
+	}
+
+	if s.path != "" {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+		log, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open relay log: %w", err)
+		}
+		s.log = log
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.Handle("POST "+CallbackPath, ingest)
+	s.mux.Handle("POST "+CallbackPath+"/", ingest)
+	s.mux.HandleFunc("GET "+EventsPath, s.authorize(s.handlePoll))
+	s.mux.HandleFunc("GET "+StreamPath, s.authorize(s.handleStream))
+	return s, nil
+}
+
+// ServeHTTP routes callbacks and client requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close closes the relay log
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.log == nil {
+		return nil
+	}
+	err := s.log.Close()
+	s.log = nil
+	return err
+}
+
+// Records returns the callbacks received after sequence number after
+func (s *Server) Records(after int64) []Record {
+	records, _ := s.since(after)
+	return records
+}
+
+// since returns the records after a sequence number and a channel closed on the next append
+func (s *Server) since(after int64) ([]Record, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Sequence numbers are contiguous, so the first record after is found by position
+	start := int(max(after-s.dropped, 0))
+	if start >= len(s.records) {
+		return nil, s.changed
+	}
+	return append([]Record(nil), s.records[start:]...), s.changed
+}
+
+func (s *Server) append(ctx context.Context, event *gladia.CallbackEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := Record{
+		Seq:      s.dropped + int64(len(s.records)) + 1,
+		Received: time.Now().UTC(),
+		Event:    *event,
+	}
+	if s.log != nil {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to encode callback: %w", err)
+		}
+		if _, err := s.log.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to persist callback: %w", err)
+		}
+	}
+
+	s.records = append(s.records, record)
+	s.trim()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return nil
+}
+
+func (s *Server) load() error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open relay log: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 32<<20)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("failed to decode relay log: %w", err)
+		}
+		record.Seq = s.dropped + int64(len(s.records)) + 1
+		s.records = append(s.records, record)
+		s.trim()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read relay log: %w", err)
+	}
+	return nil
+}
+
+// trim drops the oldest records beyond the limit. The caller must hold s.mu.
+func (s *Server) trim() {
+	if n := len(s.records) - s.maxRecords; n > 0 {
+		s.records = append(s.records[:0], s.records[n:]...)
+		s.dropped += int64(n)
+	}
+}
+
+// authorize rejects client requests without the client token
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.clientToken != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.clientToken)) != 1 {
+				http.Error(w, "invalid client token", http.StatusUnauthorized)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// handlePoll answers GET /events?after=N&wait=25s with the records after N, holding the
+// request open until one arrives or the wait elapses
+func (s *Server) handlePoll(w http.ResponseWriter, r *http.Request) {
+	after, err := parseSeq(r.URL.Query().Get("after"))
+	if err != nil {
+		http.Error(w, "invalid after", http.StatusBadRequest)
+		return
+	}
+	wait := defaultLongPoll
+	if value := r.URL.Query().Get("wait"); value != "" {
+		if wait, err = time.ParseDuration(value); err != nil || wait < 0 {
+			http.Error(w, "invalid wait", http.StatusBadRequest)
+			return
+		}
+	}
+	wait = min(wait, maxLongPoll)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		records, changed := s.since(after)
+		if len(records) > 0 || wait == 0 {
+			writeJSON(w, records)
+			return
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			writeJSON(w, []Record{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleStream sends records as server-sent events, starting after the after query
+// parameter or the Last-Event-ID header
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("after")
+	}
+	after, err := parseSeq(value)
+	if err != nil {
+		http.Error(w, "invalid after", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		records, changed := s.since(after)
+		for _, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\n", record.Seq)
+			// The event type comes from the callback body; a line break would let it inject fields
+			if name := record.Event.Event; name != "" && !strings.ContainsAny(name, "\r\n") {
+				fmt.Fprintf(w, "event: %s\n", name)
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			after = record.Seq
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func parseSeq(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,161 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/webhook"
+)
+
+func TestClientToken(t *testing.T) {
+	if _, err := NewServer(); err == nil {
+		t.Fatal("expected a relay without a client token to be refused")
+	}
+
+	if _, err := NewServer(WithClientToken("s3cret")); !errors.Is(err, webhook.ErrNoAuthentication) {
+		t.Errorf("relay without webhook authentication = %v, want ErrNoAuthentication", err)
+	}
+	if _, err := NewServer(WithClientToken("s3cret"), WithWebhookOptions(webhook.WithToken(""))); !errors.Is(err, webhook.ErrEmptyToken) {
+		t.Errorf("relay with an empty webhook token = %v, want ErrEmptyToken", err)
+	}
+
+	server, err := NewServer(WithClientToken("s3cret"), WithWebhookOptions(webhook.WithToken("s3cret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	tests := []struct {
+		name    string
+		client  *Client
+		wantErr bool
+	}{
+		{name: "no token", client: NewClient(ts.URL, WithLongPoll(0)), wantErr: true},
+		{name: "wrong token", client: NewClient(ts.URL, WithLongPoll(0), WithToken("x")), wantErr: true},
+		{name: "token", client: NewClient(ts.URL, WithLongPoll(0), WithToken("s3cret"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.Poll(context.Background(), 0)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Poll error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStreamEventNames(t *testing.T) {
+	server, err := NewServer(WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	server.append(ctx, &gladia.CallbackEvent{ID: "a", Event: "transcription.success"})
+	server.append(ctx, &gladia.CallbackEvent{ID: "b", Event: "x\ndata: {\"seq\": 99}\n\nevent: forged"})
+
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+StreamPath, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	buf := make([]byte, 4096)
+	var body strings.Builder
+	for strings.Count(body.String(), "\n\n") < 2 {
+		n, err := resp.Body.Read(buf)
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		body.Write(buf[:n])
+	}
+
+	var seqs []int64
+	client := NewClient(ts.URL)
+	streamCtx, stop := context.WithCancel(ctx)
+	client.Stream(streamCtx, 0, func(record Record) error {
+		seqs = append(seqs, record.Seq)
+		if len(seqs) == 2 {
+			stop()
+		}
+		return nil
+	})
+
+	if !strings.Contains(body.String(), "event: transcription.success\n") {
+		t.Errorf("safe event name missing from stream:\n%s", body.String())
+	}
+	if strings.Contains(body.String(), "\nevent: forged") || strings.Contains(body.String(), "\nevent: x") {
+		t.Errorf("event name with a line break was written:\n%s", body.String())
+	}
+	if len(seqs) != 2 || seqs[0] != 1 || seqs[1] != 2 {
+		t.Errorf("streamed records %v, want [1 2]", seqs)
+	}
+}
+
+func TestMaxRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "callbacks.jsonl")
+	server, err := NewServer(WithInsecure(), WithLogFile(path), WithMaxRecords(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for i := range 5 {
+		if err := server.append(ctx, &gladia.CallbackEvent{ID: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server.Close()
+
+	seqs := func(records []Record) string {
+		var out []int64
+		for _, record := range records {
+			out = append(out, record.Seq)
+		}
+		return fmt.Sprint(out)
+	}
+
+	tests := []struct {
+		name  string
+		after int64
+		want  string
+	}{
+		{name: "from the start", after: 0, want: "[3 4 5]"},
+		{name: "after dropped records", after: 1, want: "[3 4 5]"},
+		{name: "after kept records", after: 3, want: "[4 5]"},
+		{name: "up to date", after: 5, want: "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seqs(server.Records(tt.after)); got != tt.want {
+				t.Errorf("Records(%d) = %s, want %s", tt.after, got, tt.want)
+			}
+		})
+	}
+
+	reloaded, err := NewServer(WithInsecure(), WithLogFile(path), WithMaxRecords(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	if got := seqs(reloaded.Records(0)); got != "[3 4 5]" {
+		t.Errorf("reloaded Records = %s, want the latest three", got)
+	}
+	reloaded.append(ctx, &gladia.CallbackEvent{ID: "next"})
+	if records := reloaded.Records(5); len(records) != 1 || records[0].Seq != 6 || records[0].Event.ID != "next" {
+		t.Errorf("Records after reload = %+v, want the next callback as 6", records)
+	}
+}
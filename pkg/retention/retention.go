@@ -0,0 +1,220 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const defaultPageSize = 100
+
+// Client is the part of gladia.Client used to purge transcriptions
+type Client interface {
+	ListTranscriptions(ctx context.Context, opts *gladia.ListOptions) (*gladia.TranscriptionList, error)
+	DeleteTranscription(ctx context.Context, transcriptionID string) error
+}
+
+// Policy selects the transcriptions to delete. Every set filter must match.
+type Policy struct {
+	// OlderThan selects transcriptions created longer ago than this
+	OlderThan time.Duration
+	// Status restricts the selection to any of these statuses
+	Status []string
+	// CustomMetadata restricts the selection to transcriptions whose metadata contains these pairs
+	CustomMetadata gladia.CustomMetadata
+}
+
+// Options configures a Retention run
+type Options struct {
+	Policy
+	// DryRun selects transcriptions and reports them without deleting anything
+	DryRun bool
+	// Concurrency is the number of deletions in flight. Defaults to 4.
+	Concurrency int
+	// Rate limits deletions per second; zero means no limit
+	Rate float64
+	// PageSize is the number of transcriptions listed per request. Defaults to 100.
+	PageSize int
+}
+
+// Item is a transcription selected by the policy
+type Item struct {
+	ID             string                `json:"id"`
+	Status         string                `json:"status"`
+	CreatedAt      time.Time             `json:"created_at"`
+	CustomMetadata gladia.CustomMetadata `json:"custom_metadata,omitempty"`
+}
+
+// Failure is a selected transcription that could not be deleted
+type Failure struct {
+	Item
+	Error string `json:"error"`
+}
+
+// Report describes a Retention run
+type Report struct {
+	DryRun   bool      `json:"dry_run"`
+	Cutoff   time.Time `json:"cutoff,omitzero"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// Scanned is the number of transcriptions listed
+	Scanned  int    `json:"scanned"`
+	Selected []Item `json:"selected"`
+	// Deleted counts the selected transcriptions deleted, including those already gone
+	Deleted int       `json:"deleted"`
+	Failed  []Failure `json:"failed,omitempty"`
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// Retention deletes the Gladia-side transcriptions selected by a policy
+type Retention struct {
+	client Client
+	opts   Options
+	now    func() time.Time
+}
+
+// New creates a Retention running opts against client
+func New(client Client, opts Options) *Retention {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+	return &Retention{client: client, opts: opts, now: time.Now}
+}
+
+// Run lists every transcription, selects those matching the policy and deletes them.
+// Everything is listed before the first deletion so paging is not disturbed. The report
+// is returned even when the run is interrupted.
+func (r *Retention) Run(ctx context.Context) (*Report, error) {
+	report := &Report{DryRun: r.opts.DryRun, Started: r.now()}
+	if r.opts.OlderThan > 0 {
+		report.Cutoff = report.Started.Add(-r.opts.OlderThan)
+	}
+	defer func() { report.Finished = r.now() }()
+
+	if err := r.selectItems(ctx, report); err != nil {
+		return report, err
+	}
+	if r.opts.DryRun {
+		return report, nil
+	}
+	return report, r.deleteItems(ctx, report)
+}
+
+// Selects reports whether a transcription matches the policy, given the cutoff time
+func (p *Policy) Selects(item *gladia.GetTranscriptionStatus, cutoff time.Time) bool {
+	if !cutoff.IsZero() && !item.CreatedAt.Before(cutoff) {
+		return false
+	}
+	if len(p.Status) > 0 && !slices.Contains(p.Status, item.Status) {
+		return false
+	}
+	return item.CustomMetadata.Matches(p.CustomMetadata)
+}
+
+func (r *Retention) selectItems(ctx context.Context, report *Report) error {
+	list := &gladia.ListOptions{
+		Limit:          r.opts.PageSize,
+		BeforeDate:     report.Cutoff,
+		Status:         r.opts.Status,
+		CustomMetadata: r.opts.CustomMetadata,
+	}
+	for {
+		page, err := r.client.ListTranscriptions(ctx, list)
+		if err != nil {
+			return fmt.Errorf("failed to list transcriptions: %w", err)
+		}
+
+		report.Scanned += len(page.Items)
+		for i := range page.Items {
+			item := &page.Items[i]
+			// Filters are applied again in case the API ignores some of them
+			if r.opts.Selects(item, report.Cutoff) {
+				report.Selected = append(report.Selected, Item{
+					ID:             item.ID,
+					Status:         item.Status,
+					CreatedAt:      item.CreatedAt,
+					CustomMetadata: item.CustomMetadata,
+				})
+			}
+		}
+
+		if page.Next == "" || len(page.Items) == 0 {
+			return nil
+		}
+		list.Offset += len(page.Items)
+	}
+}
+
+func (r *Retention) deleteItems(ctx context.Context, report *Report) error {
+	var throttle <-chan time.Time
+	if r.opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / r.opts.Rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, r.opts.Concurrency)
+
+	var err error
+loop:
+	for _, item := range report.Selected {
+		if throttle != nil {
+			select {
+			case <-throttle:
+			case <-ctx.Done():
+				err = ctx.Err()
+				break loop
+			}
+		}
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break loop
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			deleteErr := r.client.DeleteTranscription(ctx, item.ID)
+			if code, ok := gladiaerrors.StatusCode(deleteErr); ok && code == http.StatusNotFound {
+				// Already gone, for example deleted by a concurrent run
+				deleteErr = nil
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if deleteErr != nil {
+				report.Failed = append(report.Failed, Failure{Item: item, Error: deleteErr.Error()})
+				return
+			}
+			report.Deleted++
+		}()
+	}
+	wg.Wait()
+	return err
+}
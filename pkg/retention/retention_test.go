@@ -0,0 +1,196 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	gladiaerrors "github.com/fulviodenza/go-gladia-client/pkg/errors"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeClient pages through its items and ignores every list filter, so the policy is
+// applied by Retention alone
+type fakeClient struct {
+	items     []gladia.GetTranscriptionStatus
+	deleteErr map[string]error
+
+	mu      sync.Mutex
+	lists   []gladia.ListOptions
+	deleted []string
+	times   []time.Time
+}
+
+func (c *fakeClient) ListTranscriptions(ctx context.Context, opts *gladia.ListOptions) (*gladia.TranscriptionList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lists = append(c.lists, *opts)
+
+	page := &gladia.TranscriptionList{}
+	if opts.Offset < len(c.items) {
+		page.Items = c.items[opts.Offset:min(opts.Offset+opts.Limit, len(c.items))]
+	}
+	if opts.Offset+opts.Limit < len(c.items) {
+		page.Next = "next"
+	}
+	return page, nil
+}
+
+func (c *fakeClient) DeleteTranscription(ctx context.Context, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = append(c.deleted, id)
+	c.times = append(c.times, time.Now())
+	return c.deleteErr[id]
+}
+
+func item(id, status string, age time.Duration, metadata gladia.CustomMetadata) gladia.GetTranscriptionStatus {
+	return gladia.GetTranscriptionStatus{ID: id, Status: status, CreatedAt: now.Add(-age), CustomMetadata: metadata}
+}
+
+func newRetention(client Client, opts Options) *Retention {
+	r := New(client, opts)
+	r.now = func() time.Time { return now }
+	return r
+}
+
+func TestPolicySelects(t *testing.T) {
+	cutoff := now.Add(-30 * 24 * time.Hour)
+	old := item("old", gladia.StatusDone, 40*24*time.Hour, gladia.CustomMetadata{"tenant": "acme"})
+	recent := item("recent", gladia.StatusDone, 24*time.Hour, gladia.CustomMetadata{"tenant": "acme"})
+	failed := item("failed", gladia.StatusError, 40*24*time.Hour, nil)
+
+	tests := []struct {
+		name   string
+		policy Policy
+		cutoff time.Time
+		item   gladia.GetTranscriptionStatus
+		want   bool
+	}{
+		{name: "no filters", item: recent, want: true},
+		{name: "older than cutoff", cutoff: cutoff, item: old, want: true},
+		{name: "newer than cutoff", cutoff: cutoff, item: recent, want: false},
+		{name: "status", policy: Policy{Status: []string{gladia.StatusError}}, item: failed, want: true},
+		{name: "other status", policy: Policy{Status: []string{gladia.StatusError}}, item: old, want: false},
+		{name: "metadata", policy: Policy{CustomMetadata: gladia.CustomMetadata{"tenant": "acme"}}, item: old, want: true},
+		{name: "missing metadata", policy: Policy{CustomMetadata: gladia.CustomMetadata{"tenant": "acme"}}, item: failed, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Selects(&tt.item, tt.cutoff); got != tt.want {
+				t.Errorf("Selects = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPagesAndDeletes(t *testing.T) {
+	client := &fakeClient{deleteErr: map[string]error{
+		"old-3": gladiaerrors.New(http.StatusNotFound, "not found"),
+		"old-4": gladiaerrors.New(http.StatusInternalServerError, "boom"),
+	}}
+	for i := range 250 {
+		age := time.Hour
+		if i%50 == 0 {
+			age = 60 * 24 * time.Hour
+		}
+		client.items = append(client.items, item(fmt.Sprintf("old-%d", i/50), gladia.StatusDone, age, nil))
+	}
+
+	report, err := newRetention(client, Options{Policy: Policy{OlderThan: 30 * 24 * time.Hour}}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(client.lists) != 3 {
+		t.Errorf("listed %d pages, want 3", len(client.lists))
+	}
+	for i, list := range client.lists {
+		if list.Offset != i*defaultPageSize || !list.BeforeDate.Equal(report.Cutoff) {
+			t.Errorf("page %d options = %+v", i, list)
+		}
+	}
+	if report.Scanned != 250 || len(report.Selected) != 5 {
+		t.Errorf("scanned %d and selected %d, want 250 and 5", report.Scanned, len(report.Selected))
+	}
+	if report.Deleted != 4 {
+		t.Errorf("deleted %d, want 4 counting the one already gone", report.Deleted)
+	}
+	if len(report.Failed) != 1 || report.Failed[0].ID != "old-4" {
+		t.Errorf("failed = %+v, want old-4", report.Failed)
+	}
+	if !report.Cutoff.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Errorf("cutoff = %v", report.Cutoff)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	client := &fakeClient{items: []gladia.GetTranscriptionStatus{
+		item("a", gladia.StatusDone, time.Hour, gladia.CustomMetadata{"tenant": "acme"}),
+		item("b", gladia.StatusDone, time.Hour, gladia.CustomMetadata{"tenant": "globex"}),
+	}}
+
+	report, err := newRetention(client, Options{
+		Policy: Policy{CustomMetadata: gladia.CustomMetadata{"tenant": "acme"}},
+		DryRun: true,
+	}).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !report.DryRun || len(report.Selected) != 1 || report.Selected[0].ID != "a" {
+		t.Errorf("report = %+v, want a selected in a dry run", report)
+	}
+	if len(client.deleted) != 0 || report.Deleted != 0 {
+		t.Errorf("dry run deleted %v", client.deleted)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	client := &fakeClient{}
+	for i := range 5 {
+		client.items = append(client.items, item(fmt.Sprint(i), gladia.StatusDone, time.Hour, nil))
+	}
+
+	if _, err := newRetention(client, Options{Rate: 50, Concurrency: 5}).Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	sort.Slice(client.times, func(i, j int) bool { return client.times[i].Before(client.times[j]) })
+	for i := 1; i < len(client.times); i++ {
+		if gap := client.times[i].Sub(client.times[i-1]); gap < 15*time.Millisecond {
+			t.Errorf("deletions %d and %d were %v apart, want about 20ms", i-1, i, gap)
+		}
+	}
+}
+
+func TestReportOnCancellation(t *testing.T) {
+	client := &fakeClient{}
+	for i := range 20 {
+		client.items = append(client.items, item(fmt.Sprint(i), gladia.StatusDone, time.Hour, nil))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	report, err := newRetention(client, Options{Rate: 100}).Run(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run = %v, want the context error", err)
+	}
+	if report == nil {
+		t.Fatal("no report for an interrupted run")
+	}
+	if len(report.Selected) != 20 || report.Deleted == 0 || report.Deleted >= 20 {
+		t.Errorf("selected %d and deleted %d, want a partial run", len(report.Selected), report.Deleted)
+	}
+	if report.Deleted != len(client.deleted) {
+		t.Errorf("report counts %d deletions, client saw %d", report.Deleted, len(client.deleted))
+	}
+	if report.Finished.IsZero() {
+		t.Error("report has no finish time")
+	}
+}
@@ -0,0 +1,324 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mode selects how query words are compared with transcript words
+type Mode int
+
+const (
+	// ModeInsensitive matches whole words ignoring case and diacritics
+	ModeInsensitive Mode = iota
+	// ModeExact matches whole words exactly, only ignoring surrounding punctuation
+	ModeExact
+	// ModePrefix matches words starting with the query words, ignoring case and diacritics
+	ModePrefix
+	// ModeFuzzy matches words within an edit distance of the query words, ignoring case and diacritics
+	ModeFuzzy
+)
+
+// Options configures a search
+type Options struct {
+	Mode Mode
+	// MaxDistance is the largest edit distance per word accepted in fuzzy mode.
+	// Defaults to one edit per four characters, with at least one.
+	MaxDistance int
+	// ContextWords is the number of words of context returned on each side of a match. Defaults to 8.
+	ContextWords int
+	// Limit caps the number of matches returned; zero means no limit
+	Limit int
+}
+
+// Match is an occurrence of a query in a transcript
+type Match struct {
+	TranscriptID string  `json:"transcript_id"`
+	Speaker      int     `json:"speaker"`
+	Channel      int     `json:"channel"`
+	Start        float64 `json:"start"`
+	End          float64 `json:"end"`
+	Text         string  `json:"text"`
+	Context      string  `json:"context"`
+	// Distance is the total edit distance of a fuzzy match
+	Distance int `json:"distance,omitempty"`
+}
+
+type token struct {
+	text    string
+	folded  string
+	start   float64
+	end     float64
+	speaker int
+	channel int
+	// utterance numbers the utterance the word belongs to; phrases do not span utterances
+	utterance int
+}
+
+type document struct {
+	id     string
+	tokens []token
+}
+
+// Index is an in-memory, time-indexed search index over transcripts
+type Index struct {
+	mu   sync.RWMutex
+	docs map[string]*document
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{docs: make(map[string]*document)}
+}
+
+// Add indexes the words of a transcription result under id, replacing any previous entry
+func (ix *Index) Add(id string, result *gladia.CompletedTranscriptionResult) {
+	doc := &document{id: id}
+	for i, u := range result.Result.Transcription.Utterances {
+		if len(u.Words) > 0 {
+			for _, w := range u.Words {
+				doc.add(w.Word, w.Start, w.End, i, u)
+			}
+			continue
+		}
+
+		// Without word timings every word gets the timing of its utterance
+		for _, field := range strings.Fields(u.Text) {
+			doc.add(field, u.Start, u.End, i, u)
+		}
+	}
+
+	ix.mu.Lock()
+	ix.docs[id] = doc
+	ix.mu.Unlock()
+}
+
+// Remove drops a transcript from the index
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	delete(ix.docs, id)
+	ix.mu.Unlock()
+}
+
+// Len returns the number of indexed transcripts
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	return len(ix.docs)
+}
+
+// LoadDir indexes every JSON transcription result in dir, using the result ID
+// or the file name as transcript ID. Other JSON files, such as a store index or batch
+// report, are skipped. It returns the number of transcripts added.
+func (ix *Index) LoadDir(dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list transcripts: %w", err)
+	}
+
+	added := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return added, fmt.Errorf("failed to read transcript: %w", err)
+		}
+
+		var result gladia.CompletedTranscriptionResult
+		if err := json.Unmarshal(data, &result); err != nil || !hasTranscription(&result) {
+			continue
+		}
+
+		id := result.ID
+		if id == "" {
+			id = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		ix.Add(id, &result)
+		added++
+	}
+
+	return added, nil
+}
+
+// hasTranscription reports whether a decoded file carries a transcription payload
+func hasTranscription(result *gladia.CompletedTranscriptionResult) bool {
+	transcription := &result.Result.Transcription
+	return len(transcription.Utterances) > 0 || transcription.FullTranscript != ""
+}
+
+// Search finds query in every indexed transcript. Matches are ordered by transcript ID
+// and start time, with fuzzy matches ordered by distance first.
+func (ix *Index) Search(query string, opts Options) []Match {
+	if opts.ContextWords <= 0 {
+		opts.ContextWords = 8
+	}
+
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if term := opts.normalize(field); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	var matches []Match
+	for _, doc := range ix.docs {
+		matches = append(matches, doc.search(terms, opts)...)
+	}
+	ix.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.TranscriptID != b.TranscriptID {
+			return a.TranscriptID < b.TranscriptID
+		}
+		return a.Start < b.Start
+	})
+
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+	return matches
+}
+
+func (d *document) add(word string, start, end float64, utterance int, u gladia.Utterance) {
+	text := trimPunctuation(word)
+	if text == "" {
+		return
+	}
+	d.tokens = append(d.tokens, token{
+		text:      text,
+		folded:    fold(text),
+		start:     start,
+		end:       end,
+		speaker:   u.Speaker,
+		channel:   u.Channel,
+		utterance: utterance,
+	})
+}
+
+func (d *document) search(terms []string, opts Options) []Match {
+	var matches []Match
+	for i := 0; i+len(terms) <= len(d.tokens); i++ {
+		distance := 0
+		matched := true
+		for j, term := range terms {
+			t := d.tokens[i+j]
+			if t.utterance != d.tokens[i].utterance {
+				matched = false
+				break
+			}
+			ok, dist := opts.compare(t, term)
+			if !ok {
+				matched = false
+				break
+			}
+			distance += dist
+		}
+		if !matched {
+			continue
+		}
+
+		first, last := d.tokens[i], d.tokens[i+len(terms)-1]
+		matches = append(matches, Match{
+			TranscriptID: d.id,
+			Speaker:      first.speaker,
+			Channel:      first.channel,
+			Start:        first.start,
+			End:          last.end,
+			Text:         d.join(i, i+len(terms)),
+			Context:      d.join(max(i-opts.ContextWords, 0), min(i+len(terms)+opts.ContextWords, len(d.tokens))),
+			Distance:     distance,
+		})
+	}
+	return matches
+}
+
+func (d *document) join(from, to int) string {
+	words := make([]string, 0, to-from)
+	for _, t := range d.tokens[from:to] {
+		words = append(words, t.text)
+	}
+	return strings.Join(words, " ")
+}
+
+func (o Options) normalize(word string) string {
+	word = trimPunctuation(word)
+	if o.Mode == ModeExact {
+		return word
+	}
+	return fold(word)
+}
+
+func (o Options) compare(t token, term string) (bool, int) {
+	switch o.Mode {
+	case ModeExact:
+		return t.text == term, 0
+	case ModePrefix:
+		return strings.HasPrefix(t.folded, term), 0
+	case ModeFuzzy:
+		limit := o.MaxDistance
+		if limit <= 0 {
+			limit = max(len([]rune(term))/4, 1)
+		}
+		distance := levenshtein(t.folded, term)
+		return distance <= limit, distance
+	default:
+		return t.folded == term, 0
+	}
+}
+
+func trimPunctuation(word string) string {
+	return strings.TrimFunc(word, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r) || unicode.IsSymbol(r)
+	})
+}
+
+// fold lowercases s and strips diacritics
+func fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
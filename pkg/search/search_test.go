@@ -0,0 +1,61 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func result(id string, utterances ...gladia.Utterance) *gladia.CompletedTranscriptionResult {
+	result := &gladia.CompletedTranscriptionResult{}
+	result.ID = id
+	result.Result.Transcription.Utterances = utterances
+	return result
+}
+
+func TestLoadDirSkipsOtherJSON(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]any{
+		"a.json":     result("a", gladia.Utterance{Text: "hello there"}),
+		"index.json": []map[string]string{{"id": "a"}},
+		"empty.json": map[string]string{"id": "b"},
+	}
+	for name, content := range files {
+		data, err := json.Marshal(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := NewIndex()
+	added, err := ix.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if added != 1 || ix.Len() != 1 {
+		t.Errorf("added %d transcripts, index holds %d, want 1", added, ix.Len())
+	}
+}
+
+func TestPhrasesStopAtUtteranceBoundaries(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("a", result("a",
+		gladia.Utterance{Speaker: 0, Start: 0, End: 1, Text: "see you"},
+		gladia.Utterance{Speaker: 1, Start: 1, End: 2, Text: "tomorrow then"},
+		gladia.Utterance{Speaker: 1, Start: 2, End: 3, Text: "see you tomorrow"},
+	))
+
+	matches := ix.Search("you tomorrow", Options{})
+	if len(matches) != 1 {
+		t.Fatalf("got %d matches, want 1: %+v", len(matches), matches)
+	}
+	if matches[0].Speaker != 1 || matches[0].Start != 2 {
+		t.Errorf("got match by speaker %d at %v, want speaker 1 at 2", matches[0].Speaker, matches[0].Start)
+	}
+}
@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const indexFile = "index.json"
+
+// FS is a Store keeping one JSON file per result in a directory, next to an index of
+// their summaries so List does not read every result
+type FS struct {
+	mu    sync.Mutex
+	dir   string
+	index map[string]Summary
+}
+
+// NewFS opens the store in dir, creating the directory if needed. A missing or
+// unreadable index is rebuilt from the stored results.
+func NewFS(dir string) (*FS, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "results"), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	s := &FS{dir: dir, index: make(map[string]Summary)}
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err == nil && json.Unmarshal(data, &s.index) == nil {
+		return s, nil
+	}
+	if err := s.Reindex(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Put writes the result and updates the index
+func (s *FS) Put(ctx context.Context, result *gladia.CompletedTranscriptionResult) error {
+	path, err := s.path(result.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeFile(path, data); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	s.index[result.ID] = Summarize(result)
+	return s.saveIndex()
+}
+
+// Get reads the result stored under id
+func (s *FS) Get(ctx context.Context, id string) (*gladia.CompletedTranscriptionResult, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result: %w", err)
+	}
+
+	result := &gladia.CompletedTranscriptionResult{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return result, nil
+}
+
+// List returns copies of the indexed summaries matching the query, newest first
+func (s *FS) List(ctx context.Context, query Query) ([]Summary, error) {
+	s.mu.Lock()
+	summaries := make([]Summary, 0, len(s.index))
+	for _, summary := range s.index {
+		if query.Matches(&summary) {
+			summary.CustomMetadata = copyMetadata(summary.CustomMetadata)
+			summaries = append(summaries, summary)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].CreatedAt.Equal(summaries[j].CreatedAt) {
+			return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+		}
+		return summaries[i].ID < summaries[j].ID
+	})
+	return query.Page(summaries), nil
+}
+
+// Delete removes the result stored under id and its index entry
+func (s *FS) Delete(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete result: %w", err)
+	}
+	if _, ok := s.index[id]; !ok {
+		return nil
+	}
+	delete(s.index, id)
+	return s.saveIndex()
+}
+
+// Reindex rebuilds the index from the stored result files
+func (s *FS) Reindex() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "results", "*.json"))
+	if err != nil {
+		return fmt.Errorf("failed to list results: %w", err)
+	}
+
+	index := make(map[string]Summary, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read result: %w", err)
+		}
+		result := &gladia.CompletedTranscriptionResult{}
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", filepath.Base(file), err)
+		}
+		index[result.ID] = Summarize(result)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = index
+	return s.saveIndex()
+}
+
+func (s *FS) saveIndex() error {
+	data, err := json.Marshal(s.index)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := writeFile(filepath.Join(s.dir, indexFile), data); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+func (s *FS) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid result id: %q", id)
+	}
+	return filepath.Join(s.dir, "results", id+".json"), nil
+}
+
+// writeFile replaces the file at path atomically
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+var epoch = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func result(id, status string, created time.Duration, metadata gladia.CustomMetadata) *gladia.CompletedTranscriptionResult {
+	return &gladia.CompletedTranscriptionResult{
+		ID:             id,
+		Status:         status,
+		CreatedAt:      epoch.Add(created),
+		CustomMetadata: metadata,
+	}
+}
+
+func ids(summaries []Summary) []string {
+	out := make([]string, len(summaries))
+	for i, s := range summaries {
+		out[i] = s.ID
+	}
+	return out
+}
+
+func TestFSRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	s, err := NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := result("a", gladia.StatusDone, 0, gladia.CustomMetadata{"tenant": "acme"})
+	stored.File.Filename = "call.wav"
+	if err := s.Put(ctx, stored); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	got, err := s.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.File.Filename != "call.wav" || got.CustomMetadata["tenant"] != "acme" {
+		t.Errorf("Get = %+v, want the stored result", got)
+	}
+	if _, err := s.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing = %v, want ErrNotFound", err)
+	}
+
+	reopened, err := NewFS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaries, err := reopened.List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Filename != "call.wav" {
+		t.Errorf("reopened List = %+v, want the indexed summary", summaries)
+	}
+
+	if err := reopened.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := reopened.Delete(ctx, "a"); err != nil {
+		t.Errorf("second Delete: %v", err)
+	}
+	if _, err := reopened.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := reopened.Put(ctx, result("../a", gladia.StatusDone, 0, nil)); err == nil {
+		t.Error("Put accepted an ID escaping the store")
+	}
+}
+
+func TestFSList(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*gladia.CompletedTranscriptionResult{
+		result("a", gladia.StatusDone, 0, gladia.CustomMetadata{"tenant": "acme"}),
+		result("b", gladia.StatusError, time.Hour, gladia.CustomMetadata{"tenant": "acme"}),
+		result("c", gladia.StatusDone, 2*time.Hour, gladia.CustomMetadata{"tenant": "globex", "order": 42}),
+		result("d", gladia.StatusDone, 2*time.Hour, nil),
+	} {
+		if err := s.Put(ctx, r); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "all newest first", query: Query{}, want: []string{"c", "d", "b", "a"}},
+		{name: "status", query: Query{Status: []string{gladia.StatusDone}}, want: []string{"c", "d", "a"}},
+		{name: "metadata", query: Query{CustomMetadata: gladia.CustomMetadata{"tenant": "acme"}}, want: []string{"b", "a"}},
+		{name: "metadata number", query: Query{CustomMetadata: gladia.CustomMetadata{"order": 42.0}}, want: []string{"c"}},
+		{name: "after", query: Query{After: epoch.Add(30 * time.Minute)}, want: []string{"c", "d", "b"}},
+		{name: "before", query: Query{Before: epoch.Add(2 * time.Hour)}, want: []string{"b", "a"}},
+		{name: "offset and limit", query: Query{Offset: 1, Limit: 2}, want: []string{"d", "b"}},
+		{name: "offset past the end", query: Query{Offset: 10}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries, err := s.List(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if got := fmt.Sprint(ids(summaries)); got != fmt.Sprint(tt.want) {
+				t.Errorf("List = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFSMetadataIsCopied(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata := gladia.CustomMetadata{"tenant": "acme", "tags": []any{"vip"}}
+	if err := s.Put(ctx, result("a", gladia.StatusDone, 0, metadata)); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	metadata["tenant"] = "changed by the caller"
+	metadata["tags"].([]any)[0] = "changed by the caller"
+
+	summaries, err := s.List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	summaries[0].CustomMetadata["tenant"] = "changed through List"
+
+	summaries, err = s.List(ctx, Query{CustomMetadata: gladia.CustomMetadata{"tenant": "acme"}})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("List = %v, want the indexed metadata unchanged", ids(summaries))
+	}
+	if got := summaries[0].CustomMetadata["tags"].([]any)[0]; got != "vip" {
+		t.Errorf("indexed tag = %v, want vip", got)
+	}
+}
+
+func TestFSConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := fmt.Sprintf("job-%02d", i)
+			if err := s.Put(ctx, result(id, gladia.StatusDone, time.Duration(i)*time.Minute, gladia.CustomMetadata{"n": i})); err != nil {
+				t.Errorf("Put: %v", err)
+				return
+			}
+			summaries, err := s.List(ctx, Query{})
+			if err != nil {
+				t.Errorf("List: %v", err)
+				return
+			}
+			for _, summary := range summaries {
+				summary.CustomMetadata["n"] = -1
+			}
+			if i%2 == 0 {
+				if err := s.Delete(ctx, id); err != nil {
+					t.Errorf("Delete: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	summaries, err := s.List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(summaries) != 10 {
+		t.Errorf("List has %d summaries, want 10", len(summaries))
+	}
+	for _, summary := range summaries {
+		if n, _ := summary.CustomMetadata.Int("n"); n < 0 {
+			t.Errorf("summary %s metadata changed through List", summary.ID)
+		}
+	}
+
+	if err := s.Reindex(); err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	reindexed, err := s.List(ctx, Query{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if fmt.Sprint(ids(reindexed)) != fmt.Sprint(ids(summaries)) {
+		t.Errorf("Reindex = %v, want %v", ids(reindexed), ids(summaries))
+	}
+}
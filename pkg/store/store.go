@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// ErrNotFound is returned when no result is stored under an ID
+var ErrNotFound = errors.New("result not found")
+
+// Store persists completed transcription results
+type Store interface {
+	// Put stores a result, replacing any result with the same ID
+	Put(ctx context.Context, result *gladia.CompletedTranscriptionResult) error
+	// Get returns the result stored under id or ErrNotFound
+	Get(ctx context.Context, id string) (*gladia.CompletedTranscriptionResult, error)
+	// List returns summaries of the stored results matching the query, newest first
+	List(ctx context.Context, query Query) ([]Summary, error)
+	// Delete removes the result stored under id. Deleting a missing result is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// Summary is the indexed part of a stored result
+type Summary struct {
+	ID             string                `json:"id"`
+	Status         string                `json:"status"`
+	CreatedAt      time.Time             `json:"created_at"`
+	CompletedAt    time.Time             `json:"completed_at,omitempty"`
+	CustomMetadata gladia.CustomMetadata `json:"custom_metadata,omitempty"`
+	Filename       string                `json:"filename,omitempty"`
+	AudioDuration  float64               `json:"audio_duration,omitempty"`
+}
+
+// Summarize returns the summary of a result. The summary holds a copy of the custom
+// metadata, so later changes to the result do not reach it.
+func Summarize(result *gladia.CompletedTranscriptionResult) Summary {
+	return Summary{
+		ID:             result.ID,
+		Status:         result.Status,
+		CreatedAt:      result.CreatedAt,
+		CompletedAt:    result.CompletedAt,
+		CustomMetadata: copyMetadata(result.CustomMetadata),
+		Filename:       result.File.Filename,
+		AudioDuration:  result.File.AudioDuration,
+	}
+}
+
+// copyMetadata deep-copies metadata, including the maps and slices decoded from JSON
+func copyMetadata(m gladia.CustomMetadata) gladia.CustomMetadata {
+	if m == nil {
+		return nil
+	}
+	copied := make(gladia.CustomMetadata, len(m))
+	for key, value := range m {
+		copied[key] = copyValue(value)
+	}
+	return copied
+}
+
+func copyValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(value))
+		for key, v := range value {
+			copied[key] = copyValue(v)
+		}
+		return copied
+	case gladia.CustomMetadata:
+		return copyMetadata(value)
+	case []any:
+		copied := make([]any, len(value))
+		for i, v := range value {
+			copied[i] = copyValue(v)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// Query filters and paginates List
+type Query struct {
+	// Status restricts results to any of these statuses
+	Status []string
+	// CustomMetadata restricts results to those whose metadata contains these pairs
+	CustomMetadata gladia.CustomMetadata
+	// After and Before restrict results by creation time
+	After  time.Time
+	Before time.Time
+	Offset int
+	Limit  int
+}
+
+// Matches reports whether a summary passes the filters of the query
+func (q *Query) Matches(s *Summary) bool {
+	if len(q.Status) > 0 && !slices.Contains(q.Status, s.Status) {
+		return false
+	}
+	if !q.After.IsZero() && !s.CreatedAt.After(q.After) {
+		return false
+	}
+	if !q.Before.IsZero() && !s.CreatedAt.Before(q.Before) {
+		return false
+	}
+	return s.CustomMetadata.Matches(q.CustomMetadata)
+}
+
+// Page applies the offset and limit of the query to matching summaries
+func (q *Query) Page(summaries []Summary) []Summary {
+	if q.Offset >= len(summaries) {
+		return nil
+	}
+	summaries = summaries[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(summaries) {
+		summaries = summaries[:q.Limit]
+	}
+	return summaries
+}
+
+// Waiter is the part of gladia.Client that waits for results
+type Waiter interface {
+	WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error)
+}
+
+// Saver waits for transcriptions and stores every completed result
+type Saver struct {
+	client Waiter
+	store  Store
+}
+
+// NewSaver creates a Saver storing the results client waits for in store
+func NewSaver(client Waiter, store Store) *Saver {
+	return &Saver{client: client, store: store}
+}
+
+// WaitForTranscription waits for the transcription and stores its result. If storing
+// fails the result is still returned along with the error.
+func (s *Saver) WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error) {
+	result, err := s.client.WaitForTranscription(ctx, transcriptionID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, result); err != nil {
+		return result, fmt.Errorf("failed to store result: %w", err)
+	}
+	return result, nil
+}
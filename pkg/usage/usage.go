@@ -0,0 +1,276 @@
+package usage
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const secondsPerHour = 3600
+
+// PriceTable describes how billed audio time is turned into cost
+type PriceTable struct {
+	Currency string `json:"currency,omitempty"`
+	// PerHour is the base price of one billed hour of audio
+	PerHour float64 `json:"per_hour"`
+	// Features holds the extra price per billed hour for each enabled feature, keyed by feature name
+	Features map[string]float64 `json:"features,omitempty"`
+}
+
+// Cost estimates the cost of billingTime seconds of audio processed with the given features
+func (p PriceTable) Cost(billingTime float64, features []string) float64 {
+	perHour := p.PerHour
+	for _, feature := range features {
+		perHour += p.Features[feature]
+	}
+	return billingTime / secondsPerHour * perHour
+}
+
+// Totals aggregates usage over a group of jobs. Durations are in seconds
+type Totals struct {
+	Jobs              int     `json:"jobs"`
+	AudioDuration     float64 `json:"audio_duration"`
+	BillingTime       float64 `json:"billing_time"`
+	TranscriptionTime float64 `json:"transcription_time"`
+	Cost              float64 `json:"cost"`
+}
+
+func (t *Totals) add(metadata gladia.TranscriptionMetadata, cost float64) {
+	t.Jobs++
+	t.AudioDuration += metadata.AudioDuration
+	t.BillingTime += metadata.BillingTime
+	t.TranscriptionTime += metadata.TranscriptionTime
+	t.Cost += cost
+}
+
+// Report is a snapshot of the usage recorded by a Tracker
+type Report struct {
+	Currency string             `json:"currency,omitempty"`
+	Total    Totals             `json:"total"`
+	APIKeys  map[string]*Totals `json:"api_keys"`
+	Tags     map[string]*Totals `json:"tags"`
+	Features map[string]*Totals `json:"features"`
+}
+
+// Tracker aggregates usage of completed transcriptions per API key, custom metadata tag and feature
+type Tracker struct {
+	mu        sync.Mutex
+	prices    PriceTable
+	report    Report
+	keySecret []byte
+}
+
+// TrackerOption configures a Tracker
+type TrackerOption func(*Tracker)
+
+// WithKeySecret sets the secret API keys are masked with, see MaskKey. Trackers sharing a
+// secret label the same key alike, so their reports can be combined. Defaults to a random
+// secret, which labels keys consistently only within one tracker.
+func WithKeySecret(secret []byte) TrackerOption {
+	return func(t *Tracker) {
+		t.keySecret = secret
+	}
+}
+
+// NewTracker creates a tracker estimating costs from prices
+func NewTracker(prices PriceTable, opts ...TrackerOption) *Tracker {
+	t := &Tracker{
+		prices: prices,
+		report: Report{
+			Currency: prices.Currency,
+			APIKeys:  make(map[string]*Totals),
+			Tags:     make(map[string]*Totals),
+			Features: make(map[string]*Totals),
+		},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if len(t.keySecret) == 0 {
+		t.keySecret = make([]byte, 32)
+		rand.Read(t.keySecret)
+	}
+	return t
+}
+
+// Record adds a transcription made with apiKey to the tracker.
+// Results that are not done are ignored. The API key is masked before being stored.
+func (t *Tracker) Record(apiKey string, result *gladia.CompletedTranscriptionResult) {
+	if result == nil || result.Status != gladia.StatusDone {
+		return
+	}
+
+	metadata := result.Result.Metadata
+	features := Features(result.RequestParams)
+	cost := t.prices.Cost(metadata.BillingTime, features)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.report.Total.add(metadata, cost)
+	group(t.report.APIKeys, MaskKey(t.keySecret, apiKey)).add(metadata, cost)
+	for key, value := range result.CustomMetadata {
+		group(t.report.Tags, key+"="+fmt.Sprint(value)).add(metadata, cost)
+	}
+	for _, feature := range features {
+		group(t.report.Features, feature).add(metadata, cost)
+	}
+}
+
+// Report returns a copy of the usage recorded so far
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return Report{
+		Currency: t.report.Currency,
+		Total:    t.report.Total,
+		APIKeys:  copyGroups(t.report.APIKeys),
+		Tags:     copyGroups(t.report.Tags),
+		Features: copyGroups(t.report.Features),
+	}
+}
+
+// Reset discards all recorded usage
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.report = Report{
+		Currency: t.prices.Currency,
+		APIKeys:  make(map[string]*Totals),
+		Tags:     make(map[string]*Totals),
+		Features: make(map[string]*Totals),
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// WriteCSV writes the report as CSV with one row per group, starting with the overall total
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{
+		{"group", "name", "jobs", "audio_duration", "billing_time", "transcription_time", "cost", "currency"},
+		r.row("total", "", r.Total),
+	}
+	for _, g := range []struct {
+		name   string
+		groups map[string]*Totals
+	}{
+		{"api_key", r.APIKeys},
+		{"tag", r.Tags},
+		{"feature", r.Features},
+	} {
+		for _, name := range sortedKeys(g.groups) {
+			rows = append(rows, r.row(g.name, name, *g.groups[name]))
+		}
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+func (r Report) row(group, name string, t Totals) []string {
+	return []string{
+		group,
+		name,
+		strconv.Itoa(t.Jobs),
+		strconv.FormatFloat(t.AudioDuration, 'f', 3, 64),
+		strconv.FormatFloat(t.BillingTime, 'f', 3, 64),
+		strconv.FormatFloat(t.TranscriptionTime, 'f', 3, 64),
+		strconv.FormatFloat(t.Cost, 'f', 4, 64),
+		r.Currency,
+	}
+}
+
+// Features returns the names of the optional features enabled on a request,
+// using the same names as the API fields
+func Features(req gladia.TranscriptionRequest) []string {
+	flags := []struct {
+		name    string
+		enabled bool
+	}{
+		{"diarization", req.Diarization},
+		{"translation", req.Translation},
+		{"subtitles", req.Subtitles},
+		{"detect_language", req.DetectLanguage},
+		{"enable_code_switching", req.EnableCodeSwitching},
+		{"custom_vocabulary", req.CustomVocabulary},
+		{"summarization", req.Summarization},
+		{"moderation", req.Moderation},
+		{"named_entity_recognition", req.NamedEntityRecognition},
+		{"chapterization", req.Chapterization},
+		{"name_consistency", req.NameConsistency},
+		{"custom_spelling", req.CustomSpelling},
+		{"structured_data_extraction", req.StructuredDataExtraction},
+		{"sentiment_analysis", req.SentimentAnalysis},
+		{"audio_to_llm", req.AudioToLLM},
+		{"sentences", req.Sentences},
+		{"display_mode", req.DisplayMode},
+		{"punctuation_enhanced", req.PunctuationEnhanced},
+	}
+
+	var features []string
+	for _, flag := range flags {
+		if flag.enabled {
+			features = append(features, flag.name)
+		}
+	}
+	return features
+}
+
+// MaskKey labels an API key with a prefix of its HMAC-SHA256 keyed with secret, such as
+// "key-3f2a9c1b7d4e". Distinct keys get distinct labels, and the key cannot be recovered
+// from its label without the secret.
+func MaskKey(secret []byte, apiKey string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(apiKey))
+	return "key-" + hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+func group(groups map[string]*Totals, name string) *Totals {
+	t, ok := groups[name]
+	if !ok {
+		t = &Totals{}
+		groups[name] = t
+	}
+	return t
+}
+
+func copyGroups(groups map[string]*Totals) map[string]*Totals {
+	c := make(map[string]*Totals, len(groups))
+	for name, t := range groups {
+		copied := *t
+		c[name] = &copied
+	}
+	return c
+}
+
+func sortedKeys(groups map[string]*Totals) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
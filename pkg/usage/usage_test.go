@@ -0,0 +1,119 @@
+package usage
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+func result(billing float64, req *gladia.TranscriptionRequest, metadata gladia.CustomMetadata) *gladia.CompletedTranscriptionResult {
+	r := &gladia.CompletedTranscriptionResult{Status: gladia.StatusDone, CustomMetadata: metadata}
+	if req != nil {
+		r.RequestParams = *req
+	}
+	r.Result.Metadata.BillingTime = billing
+	r.Result.Metadata.AudioDuration = billing
+	return r
+}
+
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{PerHour: 0.6, Features: map[string]float64{"diarization": 0.12}}
+	if got := prices.Cost(1800, nil); math.Abs(got-0.3) > 1e-9 {
+		t.Errorf("Cost = %v, want 0.3", got)
+	}
+	if got := prices.Cost(3600, []string{"diarization", "unpriced"}); math.Abs(got-0.72) > 1e-9 {
+		t.Errorf("Cost with features = %v, want 0.72", got)
+	}
+}
+
+func TestTrackerRecord(t *testing.T) {
+	tracker := NewTracker(PriceTable{Currency: "USD", PerHour: 0.6, Features: map[string]float64{"diarization": 0.12}})
+
+	tracker.Record("key-aaaa-1234", result(3600, &gladia.TranscriptionRequest{Diarization: true}, gladia.CustomMetadata{"tenant": "acme"}))
+	tracker.Record("key-bbbb-1234", result(1800, nil, gladia.CustomMetadata{"tenant": "globex"}))
+	tracker.Record("key-bbbb-1234", result(0, nil, nil))
+	tracker.Record("key-aaaa-1234", &gladia.CompletedTranscriptionResult{Status: gladia.StatusError})
+	tracker.Record("key-aaaa-1234", nil)
+
+	report := tracker.Report()
+	if report.Total.Jobs != 3 || report.Total.BillingTime != 5400 {
+		t.Errorf("total = %+v, want 3 jobs and 5400s billed", report.Total)
+	}
+	if math.Abs(report.Total.Cost-1.02) > 1e-9 {
+		t.Errorf("total cost = %v, want 1.02", report.Total.Cost)
+	}
+	if len(report.APIKeys) != 2 {
+		t.Errorf("api keys = %v, want keys sharing a suffix kept apart", report.APIKeys)
+	}
+	for label := range report.APIKeys {
+		if strings.Contains(label, "1234") {
+			t.Errorf("label %q reveals part of the key", label)
+		}
+	}
+	if got := report.Tags["tenant=acme"]; got == nil || got.Jobs != 1 {
+		t.Errorf("tenant=acme = %+v", got)
+	}
+	if got := report.Features["diarization"]; got == nil || math.Abs(got.Cost-0.72) > 1e-9 {
+		t.Errorf("diarization = %+v", got)
+	}
+
+	// Reports are copies
+	report.Total.Jobs = 100
+	report.Tags["tenant=acme"].Jobs = 100
+	if again := tracker.Report(); again.Total.Jobs != 3 || again.Tags["tenant=acme"].Jobs != 1 {
+		t.Error("changing a report changed the tracker")
+	}
+
+	tracker.Reset()
+	if report := tracker.Report(); report.Total.Jobs != 0 || len(report.APIKeys) != 0 || report.Currency != "USD" {
+		t.Errorf("report after Reset = %+v", report)
+	}
+}
+
+func TestMaskKey(t *testing.T) {
+	secret := []byte("secret")
+	a, b := MaskKey(secret, "gladia-key-0001"), MaskKey(secret, "other-key-0001")
+	if a == b {
+		t.Errorf("keys sharing a suffix got the same label %q", a)
+	}
+	if a != MaskKey(secret, "gladia-key-0001") {
+		t.Error("the same key got different labels")
+	}
+	if a == MaskKey([]byte("other"), "gladia-key-0001") {
+		t.Error("the label does not depend on the secret")
+	}
+
+	first := NewTracker(PriceTable{}, WithKeySecret(secret))
+	second := NewTracker(PriceTable{}, WithKeySecret(secret))
+	first.Record("gladia-key-0001", result(60, nil, nil))
+	second.Record("gladia-key-0001", result(60, nil, nil))
+	for label := range first.Report().APIKeys {
+		if _, ok := second.Report().APIKeys[label]; !ok || label != a {
+			t.Errorf("trackers sharing a secret labelled the key %q and %v", label, second.Report().APIKeys)
+		}
+	}
+}
+
+func TestReportCSV(t *testing.T) {
+	tracker := NewTracker(PriceTable{Currency: "EUR", PerHour: 1}, WithKeySecret([]byte("s")))
+	tracker.Record("k", result(3600, &gladia.TranscriptionRequest{Summarization: true}, gladia.CustomMetadata{"team": "a"}))
+
+	var buf bytes.Buffer
+	if err := tracker.Report().WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"group,name,jobs,audio_duration,billing_time,transcription_time,cost,currency",
+		"total,,1,3600.000,3600.000,0.000,1.0000,EUR",
+		"api_key," + MaskKey([]byte("s"), "k") + ",1,3600.000,3600.000,0.000,1.0000,EUR",
+		"tag,team=a,1,3600.000,3600.000,0.000,1.0000,EUR",
+		"feature,summarization,1,3600.000,3600.000,0.000,1.0000,EUR",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("csv:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
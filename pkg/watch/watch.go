@@ -0,0 +1,435 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/export"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// ProfileFile is the name of the file holding the options profile of a folder
+const ProfileFile = ".gladia.json"
+
+// DefaultFormats are the outputs written when a profile does not list any
+var DefaultFormats = []string{"json", "srt", "vtt", "txt"}
+
+// Transcriber is the part of gladia.Client used to transcribe dropped files
+type Transcriber interface {
+	UploadReader(ctx context.Context, filename string, r io.Reader) (*gladia.UploadResponse, error)
+	TranscribeWithRequest(ctx context.Context, req *gladia.TranscriptionRequest) (*gladia.TranscriptionResponse, error)
+	WaitForTranscription(ctx context.Context, transcriptionID string, opts ...gladia.WaitOption) (*gladia.CompletedTranscriptionResult, error)
+}
+
+// Profile holds the transcription options of a folder
+type Profile struct {
+	Request gladia.TranscriptionRequest `json:"request"`
+	// Formats lists the outputs to write, defaulting to DefaultFormats
+	Formats []string `json:"formats,omitempty"`
+}
+
+// LoadProfile reads a profile from a JSON file
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile: %w", err)
+	}
+	profile := &Profile{}
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("failed to decode profile %s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// EventKind is the stage of a file reported by an Event
+type EventKind string
+
+// Stages of a watched file
+const (
+	EventStarted EventKind = "started"
+	EventDone    EventKind = "done"
+	EventFailed  EventKind = "failed"
+)
+
+// Event reports progress on a file
+type Event struct {
+	Kind EventKind
+	Path string
+	// Outputs lists the files written for a done event
+	Outputs []string
+	Err     error
+}
+
+// Options configures a Watcher
+type Options struct {
+	// Profile is used for folders without a profile file
+	Profile Profile
+	// OutputDir receives the outputs; empty writes them next to the processed file
+	OutputDir string
+	// DoneDir and FailedDir receive processed files. Relative paths are resolved against
+	// the folder of each file. Default to "done" and "failed".
+	DoneDir   string
+	FailedDir string
+	// PollInterval is how often the directory is scanned. Defaults to 2 seconds.
+	PollInterval time.Duration
+	// StableFor is how long a file size must stay unchanged before it is processed.
+	// Defaults to 5 seconds.
+	StableFor time.Duration
+	// Concurrency is the number of files processed at once. Defaults to 2.
+	Concurrency int
+	// Recursive also watches subfolders, each with its own profile
+	Recursive bool
+	// WaitOptions are passed to WaitForTranscription
+	WaitOptions []gladia.WaitOption
+	// OnEvent is called as files are processed, possibly from several goroutines
+	OnEvent func(Event)
+}
+
+// Watcher transcribes audio files dropped into a directory. Directories are scanned
+// periodically rather than through file system notifications, so shared and network
+// folders work too.
+type Watcher struct {
+	client Transcriber
+	dir    string
+	opts   Options
+
+	mu      sync.Mutex
+	pending map[string]*candidate
+	active  map[string]bool
+	// processed holds files that could not be moved out of the folder after processing,
+	// so they are not submitted again until they change
+	processed map[string]candidate
+
+	// moveMu serializes moves so concurrent files with the same name get distinct names
+	moveMu sync.Mutex
+}
+
+type candidate struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// New creates a watcher of dir
+func New(client Transcriber, dir string, opts Options) *Watcher {
+	if opts.DoneDir == "" {
+		opts.DoneDir = "done"
+	}
+	if opts.FailedDir == "" {
+		opts.FailedDir = "failed"
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.StableFor <= 0 {
+		opts.StableFor = 5 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 2
+	}
+	return &Watcher{
+		client:    client,
+		dir:       dir,
+		opts:      opts,
+		pending:   make(map[string]*candidate),
+		active:    make(map[string]bool),
+		processed: make(map[string]candidate),
+	}
+}
+
+// Run scans the directory until ctx is done, then waits for files being processed
+func (w *Watcher) Run(ctx context.Context) error {
+	if _, err := os.Stat(w.dir); err != nil {
+		return fmt.Errorf("failed to watch directory: %w", err)
+	}
+
+	semaphore := make(chan struct{}, w.opts.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		ready, err := w.scan(time.Now())
+		if err != nil {
+			return err
+		}
+		for _, path := range ready {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				w.ProcessFile(ctx, path)
+				w.mu.Lock()
+				delete(w.active, path)
+				w.mu.Unlock()
+			}()
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// scan returns the files whose size has been stable long enough and marks them active
+func (w *Watcher) scan(now time.Time) ([]string, error) {
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(w.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != w.dir && (!w.opts.Recursive || w.skipDir(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || !isAudio(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		seen[path] = true
+		w.observe(path, info, now)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for path := range w.processed {
+		if !seen[path] {
+			delete(w.processed, path)
+		}
+	}
+
+	var ready []string
+	for path, c := range w.pending {
+		if !seen[path] {
+			delete(w.pending, path)
+			continue
+		}
+		if c.size > 0 && now.Sub(c.since) >= w.opts.StableFor && !w.active[path] {
+			ready = append(ready, path)
+			w.active[path] = true
+			delete(w.pending, path)
+		}
+	}
+	return ready, nil
+}
+
+func (w *Watcher) observe(path string, info fs.FileInfo, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.active[path] {
+		return
+	}
+	if done, ok := w.processed[path]; ok {
+		if done.size == info.Size() && done.modTime.Equal(info.ModTime()) {
+			return
+		}
+		delete(w.processed, path)
+	}
+	c, ok := w.pending[path]
+	if !ok || c.size != info.Size() || !c.modTime.Equal(info.ModTime()) {
+		w.pending[path] = &candidate{size: info.Size(), modTime: info.ModTime(), since: now}
+	}
+}
+
+// skipDir reports whether a subfolder holds processed files or outputs
+func (w *Watcher) skipDir(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	for _, dir := range []string{w.opts.DoneDir, w.opts.FailedDir, w.opts.OutputDir} {
+		if dir == "" {
+			continue
+		}
+		if filepath.IsAbs(dir) && filepath.Clean(dir) == filepath.Clean(path) {
+			return true
+		}
+		if !filepath.IsAbs(dir) && name == filepath.Base(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessFile uploads and transcribes one file, writes its outputs and moves it to the
+// done or failed folder. Files interrupted by ctx are left where they are. A file that
+// cannot be moved is not processed again by later scans unless it changes.
+func (w *Watcher) ProcessFile(ctx context.Context, path string) error {
+	w.emit(Event{Kind: EventStarted, Path: path})
+	info, _ := os.Stat(path)
+
+	outputs, err := w.transcribe(ctx, path)
+	if err != nil && ctx.Err() != nil {
+		// Interrupted files stay in place and are picked up by the next run
+		w.emit(Event{Kind: EventFailed, Path: path, Err: err})
+		return err
+	}
+	if err != nil {
+		moved, moveErr := w.move(path, w.opts.FailedDir)
+		if moveErr == nil {
+			message := []byte(err.Error() + "\n")
+			sidecar := strings.TrimSuffix(moved, filepath.Ext(moved)) + ".error.txt"
+			if writeErr := os.WriteFile(sidecar, message, 0o644); writeErr != nil {
+				moveErr = fmt.Errorf("failed to write error file: %w", writeErr)
+			}
+		}
+		err = errors.Join(err, moveErr)
+		w.remember(path, info)
+		w.emit(Event{Kind: EventFailed, Path: path, Err: err})
+		return err
+	}
+
+	w.emit(Event{Kind: EventDone, Path: path, Outputs: outputs})
+	return nil
+}
+
+func (w *Watcher) transcribe(ctx context.Context, path string) ([]string, error) {
+	profile, err := w.profile(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	upload, err := w.client.UploadReader(ctx, filepath.Base(path), file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req := profile.Request
+	req.AudioURL = upload.AudioURL
+	job, err := w.client.TranscribeWithRequest(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := w.client.WaitForTranscription(ctx, job.ID, w.opts.WaitOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := w.opts.OutputDir
+	if dir == "" {
+		dir = filepath.Dir(path)
+	}
+	formats := profile.Formats
+	if len(formats) == 0 {
+		formats = DefaultFormats
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	outputs, err := export.WriteFiles(dir, name, result, formats, export.Options{Title: name})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.move(path, w.opts.DoneDir); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// profile returns the profile of the folder, or the default one if it has none
+func (w *Watcher) profile(dir string) (*Profile, error) {
+	path := filepath.Join(dir, ProfileFile)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		profile := w.opts.Profile
+		return &profile, nil
+	}
+	return LoadProfile(path)
+}
+
+// target resolves a done or failed folder against the folder of the file
+func (w *Watcher) target(path, dir string) string {
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(path), dir)
+}
+
+// remember records a file still in the folder after processing, as it was before
+// processing started
+func (w *Watcher) remember(path string, info fs.FileInfo) {
+	if info == nil {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.processed[path] = candidate{size: info.Size(), modTime: info.ModTime()}
+}
+
+// move puts the file into a done or failed folder. A file already there with the same
+// name is kept and the moved file gets a numbered name, such as call-1.wav.
+func (w *Watcher) move(path, dir string) (string, error) {
+	target := w.target(path, dir)
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", target, err)
+	}
+
+	w.moveMu.Lock()
+	defer w.moveMu.Unlock()
+
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	moved := filepath.Join(target, name)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(moved); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		moved = filepath.Join(target, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+	if err := os.Rename(path, moved); err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
+	}
+	return moved, nil
+}
+
+func (w *Watcher) emit(event Event) {
+	if w.opts.OnEvent != nil {
+		w.opts.OnEvent(event)
+	}
+}
+
+// isAudio reports whether the file name has an audio or video extension accepted by Gladia
+func isAudio(name string) bool {
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(name), ".")) {
+	case "wav", "mp3", "flac", "ogg", "oga", "opus", "m4a", "aac", "webm", "mp4", "mov", "mkv", "wma", "amr":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,137 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+	"github.com/fulviodenza/go-gladia-client/pkg/gladiatest"
+)
+
+func newWatcher(t *testing.T, dir string, opts Options) (*Watcher, *gladiatest.Server) {
+	t.Helper()
+	server := gladiatest.NewServer()
+	t.Cleanup(server.Close)
+	opts.WaitOptions = []gladia.WaitOption{gladia.WithPollInterval(10 * time.Millisecond)}
+	return New(server.Client(), dir, opts), server
+}
+
+func writeAudio(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("audio"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func list(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestProcessFileWritesOutputsNextToTheFile(t *testing.T) {
+	dir := t.TempDir()
+	w, _ := newWatcher(t, dir, Options{Profile: Profile{Formats: []string{"txt"}}})
+	path := filepath.Join(dir, "call.wav")
+	writeAudio(t, path)
+
+	var events []EventKind
+	w.opts.OnEvent = func(e Event) { events = append(events, e.Kind) }
+	if err := w.ProcessFile(context.Background(), path); err != nil {
+		t.Fatalf("ProcessFile: %v", err)
+	}
+
+	if got := list(t, dir); !slices.Equal(got, []string{"call.txt", "done"}) {
+		t.Errorf("folder = %v, want the output next to the done folder", got)
+	}
+	if got := list(t, filepath.Join(dir, "done")); !slices.Equal(got, []string{"call.wav"}) {
+		t.Errorf("done = %v, want only the audio", got)
+	}
+	if !slices.Equal(events, []EventKind{EventStarted, EventDone}) {
+		t.Errorf("events = %v", events)
+	}
+}
+
+func TestMoveKeepsFilesWithTheSameName(t *testing.T) {
+	dir := t.TempDir()
+	output := t.TempDir()
+	w, _ := newWatcher(t, dir, Options{OutputDir: output, Profile: Profile{Formats: []string{"txt"}}})
+	ctx := context.Background()
+	path := filepath.Join(dir, "call.wav")
+
+	for range 3 {
+		writeAudio(t, path)
+		if err := w.ProcessFile(ctx, path); err != nil {
+			t.Fatalf("ProcessFile: %v", err)
+		}
+	}
+
+	want := []string{"call-1.wav", "call-2.wav", "call.wav"}
+	if got := list(t, filepath.Join(dir, "done")); !slices.Equal(got, want) {
+		t.Errorf("done = %v, want %v", got, want)
+	}
+}
+
+func TestUnmovableFileIsNotResubmitted(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(t.TempDir(), "not-a-directory")
+	writeAudio(t, blocked)
+	w, _ := newWatcher(t, dir, Options{
+		OutputDir: t.TempDir(),
+		DoneDir:   blocked,
+		FailedDir: blocked,
+		StableFor: time.Second,
+		Profile:   Profile{Formats: []string{"txt"}},
+	})
+	ctx := context.Background()
+	path := filepath.Join(dir, "call.wav")
+	writeAudio(t, path)
+
+	now := time.Now()
+	scanAt := func(at time.Time) []string {
+		t.Helper()
+		ready, err := w.scan(at)
+		if err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		return ready
+	}
+
+	scanAt(now)
+	ready := scanAt(now.Add(time.Second))
+	if !slices.Equal(ready, []string{path}) {
+		t.Fatalf("ready = %v, want the stable file", ready)
+	}
+	if err := w.ProcessFile(ctx, path); err == nil {
+		t.Fatal("ProcessFile succeeded without a folder to move the file to")
+	}
+	w.mu.Lock()
+	delete(w.active, path)
+	w.mu.Unlock()
+
+	scanAt(now.Add(2 * time.Second))
+	if ready := scanAt(now.Add(time.Hour)); len(ready) != 0 {
+		t.Errorf("ready = %v, want the processed file skipped", ready)
+	}
+
+	// A changed file is new audio and is processed again
+	later := now.Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	scanAt(now.Add(2 * time.Hour))
+	if ready := scanAt(now.Add(3 * time.Hour)); !slices.Equal(ready, []string{path}) {
+		t.Errorf("ready = %v, want the changed file", ready)
+	}
+}
@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+// Defaults of a Handler
+const (
+	DefaultSignatureHeader = "X-Webhook-Signature"
+	DefaultTimestampHeader = "X-Webhook-Timestamp"
+	DefaultTokenParam      = "token"
+	DefaultTolerance       = 5 * time.Minute
+	DefaultReplayWindow    = 24 * time.Hour
+	defaultMaxBodySize     = 32 << 20
+)
+
+// Errors returned when a handler is misconfigured or an event cannot be identified
+var (
+	ErrNoAuthentication = errors.New("webhook handler has no secret or token; use WithInsecure to accept unauthenticated callbacks")
+	ErrEmptySecret      = errors.New("webhook secret is empty")
+	ErrEmptyToken       = errors.New("webhook token is empty")
+	ErrMissingEventID   = errors.New("callback event has no ID")
+)
+
+// HandlerFunc processes an authenticated callback event. Returning an error answers
+// with a server error so the sender retries.
+type HandlerFunc func(ctx context.Context, event *gladia.CallbackEvent) error
+
+// Handler is an http.Handler receiving Gladia callbacks. It authenticates requests with
+// a signature, a URL token or both, rejects replayed events and passes the rest on.
+type Handler struct {
+	handle          HandlerFunc
+	secret          []byte
+	signatureHeader string
+	timestampHeader string
+	tolerance       time.Duration
+	token           string
+	tokenParam      string
+	store           IdempotencyStore
+	replayWindow    time.Duration
+	maxBodySize     int64
+	insecure        bool
+	// err records a misconfiguration; every request is refused while it is set
+	err error
+	now func() time.Time
+}
+
+// HandlerOption configures a Handler
+type HandlerOption func(*Handler)
+
+// WithSecret requires an HMAC-SHA256 signature made with secret, see Verify.
+// An empty secret is a misconfiguration and makes the handler refuse every request.
+func WithSecret(secret []byte) HandlerOption {
+	return func(h *Handler) {
+		if len(secret) == 0 {
+			h.err = ErrEmptySecret
+			return
+		}
+		h.secret = secret
+	}
+}
+
+// WithSignatureHeaders sets the headers carrying the signature and its timestamp
+func WithSignatureHeaders(signature, timestamp string) HandlerOption {
+	return func(h *Handler) {
+		h.signatureHeader = signature
+		h.timestampHeader = timestamp
+	}
+}
+
+// WithTolerance sets how far a signature timestamp may be from the current time
+func WithTolerance(tolerance time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.tolerance = tolerance
+	}
+}
+
+// WithToken requires the callback URL to carry token in its query, see VerifyToken.
+// Unlike a signature the token carries no timestamp, so a captured request stays valid:
+// it is only turned away while its event ID is within the replay window. Combine it with
+// WithSecret when the sender can sign. An empty token makes the handler refuse every request.
+func WithToken(token string) HandlerOption {
+	return func(h *Handler) {
+		if token == "" {
+			h.err = ErrEmptyToken
+			return
+		}
+		h.token = token
+	}
+}
+
+// WithTokenParam sets the query parameter holding the token
+func WithTokenParam(param string) HandlerOption {
+	return func(h *Handler) {
+		h.tokenParam = param
+	}
+}
+
+// WithIdempotencyStore sets where processed event IDs are remembered and for how long
+func WithIdempotencyStore(store IdempotencyStore, window time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.store = store
+		h.replayWindow = window
+	}
+}
+
+// WithInsecure accepts callbacks without authentication, for local development only
+func WithInsecure() HandlerOption {
+	return func(h *Handler) {
+		h.insecure = true
+	}
+}
+
+// WithMaxBodySize limits the size of accepted callback bodies
+func WithMaxBodySize(size int64) HandlerOption {
+	return func(h *Handler) {
+		h.maxBodySize = size
+	}
+}
+
+// NewHandler creates a callback handler passing authenticated events to handle.
+// It refuses every request unless configured with WithSecret, WithToken or WithInsecure.
+func NewHandler(handle HandlerFunc, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		handle:          handle,
+		signatureHeader: DefaultSignatureHeader,
+		timestampHeader: DefaultTimestampHeader,
+		tolerance:       DefaultTolerance,
+		tokenParam:      DefaultTokenParam,
+		store:           NewMemoryStore(),
+		replayWindow:    DefaultReplayWindow,
+		maxBodySize:     defaultMaxBodySize,
+		now:             time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Err reports the misconfiguration that makes the handler refuse every request, or nil
+func (h *Handler) Err() error {
+	if h.err != nil {
+		return h.err
+	}
+	if h.secret == nil && h.token == "" && !h.insecure {
+		return ErrNoAuthentication
+	}
+	return nil
+}
+
+// ServeHTTP authenticates and dispatches a callback
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := h.Verify(r, body); err != nil {
+		status := http.StatusUnauthorized
+		if h.err != nil || errors.Is(err, ErrNoAuthentication) {
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	event := &gladia.CallbackEvent{}
+	if err := json.Unmarshal(body, event); err != nil {
+		http.Error(w, "invalid callback body", http.StatusBadRequest)
+		return
+	}
+
+	key, err := EventKey(event)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	first, err := h.store.Claim(ctx, key, h.replayWindow)
+	if err != nil {
+		http.Error(w, "failed to check event", http.StatusInternalServerError)
+		return
+	}
+	if !first {
+		// Acknowledge so the sender stops retrying an event already handled
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := h.handle(ctx, event); err != nil {
+		h.store.Release(context.WithoutCancel(ctx), key)
+		http.Error(w, "failed to process event", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Verify authenticates a callback request whose body was already read
+func (h *Handler) Verify(r *http.Request, body []byte) error {
+	if err := h.Err(); err != nil {
+		return err
+	}
+	if h.token != "" {
+		if err := VerifyToken(h.token, r.URL.Query().Get(h.tokenParam)); err != nil {
+			return err
+		}
+	}
+	if h.secret != nil {
+		signature := r.Header.Get(h.signatureHeader)
+		timestamp := r.Header.Get(h.timestampHeader)
+		if err := Verify(h.secret, body, signature, timestamp, h.tolerance, h.now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EventKey identifies a callback event for replay protection. Gladia sends the
+// transcription ID as the event ID, so the event type is part of the key. Events without
+// an ID cannot be told apart and are rejected.
+func EventKey(event *gladia.CallbackEvent) (string, error) {
+	id := event.ID
+	if id == "" && event.Payload != nil {
+		id = event.Payload.ID
+	}
+	if id == "" {
+		return "", ErrMissingEventID
+	}
+	return fmt.Sprintf("%s:%s", event.Event, id), nil
+}
+
+// TokenURL adds token to the query of a callback URL under DefaultTokenParam
+func TokenURL(callbackURL, token string) (string, error) {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse callback url: %w", err)
+	}
+	query := u.Query()
+	query.Set(DefaultTokenParam, token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fulviodenza/go-gladia-client/pkg/gladia"
+)
+
+const event = `{"id": "job-1", "event": "transcription.success"}`
+
+func TestHandler(t *testing.T) {
+	secret := []byte("s3cret")
+	signed := func(r *http.Request) {
+		now := time.Now()
+		r.Header.Set(DefaultSignatureHeader, Sign(secret, []byte(event), now))
+		r.Header.Set(DefaultTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	}
+
+	tests := []struct {
+		name    string
+		opts    []HandlerOption
+		target  string
+		body    string
+		prepare func(r *http.Request)
+		want    int
+	}{
+		{name: "no authentication configured", want: http.StatusInternalServerError},
+		{name: "empty secret", opts: []HandlerOption{WithSecret([]byte{}), WithToken("t")}, target: "/?token=t", want: http.StatusInternalServerError},
+		{name: "empty token", opts: []HandlerOption{WithToken("")}, want: http.StatusInternalServerError},
+		{name: "insecure", opts: []HandlerOption{WithInsecure()}, want: http.StatusOK},
+		{name: "token", opts: []HandlerOption{WithToken("t")}, target: "/?token=t", want: http.StatusOK},
+		{name: "wrong token", opts: []HandlerOption{WithToken("t")}, target: "/?token=x", want: http.StatusUnauthorized},
+		{name: "signature", opts: []HandlerOption{WithSecret(secret)}, prepare: signed, want: http.StatusOK},
+		{name: "unsigned", opts: []HandlerOption{WithSecret(secret)}, want: http.StatusUnauthorized},
+		{name: "event without ID", opts: []HandlerOption{WithInsecure()}, body: `{"event": "transcription.success"}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled := 0
+			handler := NewHandler(func(ctx context.Context, event *gladia.CallbackEvent) error {
+				handled++
+				return nil
+			}, tt.opts...)
+
+			body, target := tt.body, tt.target
+			if body == "" {
+				body = event
+			}
+			if target == "" {
+				target = "/"
+			}
+			r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+			if tt.prepare != nil {
+				tt.prepare(r)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if wantHandled := tt.want == http.StatusOK; (handled == 1) != wantHandled {
+				t.Errorf("handled %d times", handled)
+			}
+		})
+	}
+}
+
+func TestReplayedEventIsHandledOnce(t *testing.T) {
+	secret := []byte("s3cret")
+	handled := 0
+	fail := true
+	handler := NewHandler(func(ctx context.Context, event *gladia.CallbackEvent) error {
+		handled++
+		if fail {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	}, WithSecret(secret))
+
+	send := func() int {
+		now := time.Now()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(event))
+		r.Header.Set(DefaultSignatureHeader, Sign(secret, []byte(event), now))
+		r.Header.Set(DefaultTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// A failed event is released so its redelivery is processed
+	if code := send(); code != http.StatusInternalServerError {
+		t.Fatalf("failing delivery status = %d, want 500", code)
+	}
+	fail = false
+	for i := range 3 {
+		if code := send(); code != http.StatusOK {
+			t.Fatalf("delivery %d status = %d, want 200", i, code)
+		}
+	}
+	if handled != 2 {
+		t.Errorf("handled %d times, want the failed delivery and one redelivery", handled)
+	}
+}
+
+func TestStaleTimestampIsRejected(t *testing.T) {
+	secret := []byte("s3cret")
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		signedAt time.Time
+		want     int
+	}{
+		{name: "within tolerance", signedAt: now.Add(-4 * time.Minute), want: http.StatusOK},
+		{name: "stale", signedAt: now.Add(-6 * time.Minute), want: http.StatusUnauthorized},
+		{name: "from the future", signedAt: now.Add(6 * time.Minute), want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(func(ctx context.Context, event *gladia.CallbackEvent) error {
+				return nil
+			}, WithSecret(secret), WithTolerance(5*time.Minute))
+			handler.now = func() time.Time { return now }
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(event))
+			r.Header.Set(DefaultSignatureHeader, Sign(secret, []byte(event), tt.signedAt))
+			r.Header.Set(DefaultTimestampHeader, strconv.FormatInt(tt.signedAt.Unix(), 10))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	err := Verify(secret, []byte(event), Sign(secret, []byte(event), now.Add(-time.Hour)), strconv.FormatInt(now.Add(-time.Hour).Unix(), 10), 5*time.Minute, now)
+	if !errors.Is(err, ErrTimestamp) {
+		t.Errorf("Verify = %v, want ErrTimestamp", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	claim := func(key string) bool {
+		t.Helper()
+		first, err := store.Claim(ctx, key, 10*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return first
+	}
+
+	if !claim("a") || claim("a") {
+		t.Fatal("a key must be claimed once")
+	}
+	claim("b")
+
+	now = now.Add(30 * time.Second)
+	if !claim("a") {
+		t.Error("expired key was not claimable again")
+	}
+	if _, ok := store.seen["b"]; !ok {
+		t.Error("keys were pruned less than a minute after the last prune")
+	}
+
+	now = now.Add(time.Minute)
+	claim("c")
+	if _, ok := store.seen["b"]; ok {
+		t.Error("expired key was not pruned")
+	}
+
+	if err := store.Release(ctx, "c"); err != nil {
+		t.Fatal(err)
+	}
+	if !claim("c") {
+		t.Error("released key was not claimable again")
+	}
+}
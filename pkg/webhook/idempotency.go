@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore remembers the events already processed
+type IdempotencyStore interface {
+	// Claim records key as seen for ttl and reports whether it was not seen before
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release forgets key so a redelivery of a failed event is processed again
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryStore is an in-process IdempotencyStore
+type MemoryStore struct {
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+	now    func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory idempotency store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		seen: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Claim records key as seen. Expired keys are pruned at most once a minute.
+func (s *MemoryStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	if expires, ok := s.seen[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.seen[key] = now.Add(ttl)
+	return true, nil
+}
+
+// prune drops expired keys, at most once a minute. The caller must hold s.mu.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.pruned) < time.Minute {
+		return
+	}
+	s.pruned = now
+	for k, expires := range s.seen {
+		if !now.Before(expires) {
+			delete(s.seen, k)
+		}
+	}
+}
+
+// Release forgets key
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.seen, key)
+	return nil
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned when a callback cannot be authenticated
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidToken     = errors.New("invalid webhook token")
+	ErrTimestamp        = errors.New("webhook timestamp outside tolerance")
+)
+
+// signaturePrefix is accepted in front of hex signatures, as sent by most signing proxies
+const signaturePrefix = "sha256="
+
+// Sign returns the hex HMAC-SHA256 of "<unix timestamp>.<body>" keyed with secret
+func Sign(secret, body []byte, timestamp time.Time) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature is the HMAC-SHA256 of timestamp and body keyed with secret,
+// and that the timestamp, in Unix seconds, is within tolerance of now. The signature may
+// list several comma-separated values, for example during secret rotation, and each may
+// carry a "sha256=" prefix. A zero tolerance skips the timestamp check.
+func Verify(secret, body []byte, signature, timestamp string, tolerance time.Duration, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrInvalidSignature, timestamp)
+	}
+	signedAt := time.Unix(seconds, 0)
+	if tolerance > 0 && (now.Sub(signedAt) > tolerance || signedAt.Sub(now) > tolerance) {
+		return ErrTimestamp
+	}
+
+	expected, _ := hex.DecodeString(Sign(secret, body, signedAt))
+	for _, candidate := range strings.Split(signature, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), signaturePrefix)
+		got, err := hex.DecodeString(candidate)
+		if err == nil && hmac.Equal(got, expected) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// VerifyToken compares a token taken from the callback URL with the expected one in
+// constant time. Gladia posts callbacks to the URL it was given, so a secret token in the
+// callback URL query authenticates callbacks without any signing support.
+func VerifyToken(expected, token string) error {
+	if token == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(token)) != 1 {
+		return ErrInvalidToken
+	}
+	return nil
+}